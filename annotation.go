@@ -0,0 +1,310 @@
+package humanize
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Annotation is a single line in the docs in @Name arg1 key=value "quoted string" format
+type Annotation struct {
+	Name string
+	// Args is the positional arguments, the quoted ones are unquoted
+	Args []string
+	// Params is the key=value arguments
+	Params map[string]string
+	Pos    token.Position
+}
+
+// Annotations is the list of annotations of a declaration
+type Annotations []*Annotation
+
+// Find return the first annotation with the name
+func (a Annotations) Find(name string) (*Annotation, bool) {
+	for i := range a {
+		if a[i].Name == name {
+			return a[i], true
+		}
+	}
+	return nil, false
+}
+
+// FindAll return all annotations with the name
+func (a Annotations) FindAll(name string) Annotations {
+	var res Annotations
+	for i := range a {
+		if a[i].Name == name {
+			res = append(res, a[i])
+		}
+	}
+	return res
+}
+
+// Param return the value of a key=value argument
+func (a *Annotation) Param(key string) (string, bool) {
+	v, ok := a.Params[key]
+	return v, ok
+}
+
+// commentLines return the text of each line in the comment, without the comment
+// markers, and the offset of each line in the comment
+func commentLines(c *ast.Comment) ([]string, []int) {
+	text := c.Text
+	if strings.HasPrefix(text, "//") {
+		return []string{text[2:]}, []int{2}
+	}
+	text = strings.TrimSuffix(text[2:], "*/")
+	var (
+		lines   []string
+		offsets []int
+		offset  = 2
+	)
+	for _, l := range strings.Split(text, "\n") {
+		lines = append(lines, l)
+		offsets = append(offsets, offset)
+		offset += len(l) + 1
+	}
+	return lines, offsets
+}
+
+// splitAnnotation split the annotation line into the fields, the quoted ones are
+// unquoted. the key="value" is a single field
+func splitAnnotation(line string) ([]string, error) {
+	res, _, err := splitFields(line)
+	return res, err
+}
+
+// splitFields is the splitAnnotation, and the index of the first = before any quote
+// for each field, it is -1 if there is none. so the key of key=value is never quoted
+func splitFields(line string) ([]string, []int, error) {
+	var (
+		res []string
+		eqs []int
+		cur strings.Builder
+		eq  = -1
+		in  bool // there is something in cur
+		q   bool // there is a quoted string in cur
+		r   = []rune(line)
+	)
+	for i := 0; i < len(r); i++ {
+		switch {
+		case unicode.IsSpace(r[i]):
+			if in {
+				res, eqs = append(res, cur.String()), append(eqs, eq)
+				cur.Reset()
+				eq, in, q = -1, false, false
+			}
+		case r[i] == '"' || r[i] == '`':
+			quote := r[i]
+			j := i + 1
+			for ; j < len(r) && r[j] != quote; j++ {
+				if r[j] == '\\' && quote == '"' {
+					j++
+				}
+			}
+			if j >= len(r) {
+				return nil, nil, fmt.Errorf("unterminated string in annotation")
+			}
+			s, err := strconv.Unquote(string(r[i : j+1]))
+			if err != nil {
+				return nil, nil, err
+			}
+			cur.WriteString(s)
+			in, q = true, true
+			i = j
+		default:
+			if r[i] == '=' && eq < 0 && !q {
+				eq = cur.Len()
+			}
+			cur.WriteRune(r[i])
+			in = true
+		}
+	}
+	if in {
+		res, eqs = append(res, cur.String()), append(eqs, eq)
+	}
+	return res, eqs, nil
+}
+
+func parseAnnotation(line string) (*Annotation, error) {
+	line = strings.TrimSpace(line)
+	if len(line) < 2 || line[0] != '@' || unicode.IsSpace(rune(line[1])) {
+		return nil, nil
+	}
+	fields, eqs, err := splitFields(line[1:])
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) == 0 || fields[0] == "" {
+		return nil, fmt.Errorf("annotation without name")
+	}
+	res := &Annotation{Name: fields[0], Params: make(map[string]string)}
+	for i, f := range fields[1:] {
+		// only an unquoted key is a key=value, "a=b" is an argument
+		if eq := eqs[i+1]; eq > 0 && isIdent(f[:eq]) {
+			res.Params[f[:eq]] = f[eq+1:]
+			continue
+		}
+		res.Args = append(res.Args, f)
+	}
+	return res, nil
+}
+
+func isIdent(s string) bool {
+	for i, r := range s {
+		if !unicode.IsLetter(r) && r != '_' && r != '-' && (i == 0 || !unicode.IsDigit(r)) {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// annotationsFromNodeDoc extract the annotations from the comments, the parse errors
+// are added to the package diagnostics
+func annotationsFromNodeDoc(f *File, cgs ...*ast.CommentGroup) Annotations {
+	var res Annotations
+	for _, cg := range cgs {
+		if cg == nil {
+			continue
+		}
+		for _, c := range cg.List {
+			lines, offsets := commentLines(c)
+			for i := range lines {
+				at := strings.Index(lines[i], "@")
+				if at < 0 || strings.TrimSpace(lines[i][:at]) != "" {
+					continue
+				}
+				pos := f.position(c.Slash + token.Pos(offsets[i]+at))
+				a, err := parseAnnotation(lines[i])
+				if err != nil {
+					if f != nil && f.pkg != nil {
+						f.pkg.Diagnostics = append(f.pkg.Diagnostics, Diagnostic{Pos: pos, Message: err.Error()})
+					}
+					continue
+				}
+				if a != nil {
+					a.Pos = pos
+					res = append(res, a)
+				}
+			}
+		}
+	}
+	return res
+}
+
+// AnnotationRule is the schema of a single annotation
+type AnnotationRule struct {
+	// MinArgs and MaxArgs is the number of positional arguments, negative MaxArgs means
+	// no limit
+	MinArgs, MaxArgs int
+	// Params is the allowed keys, nil means any key is allowed
+	Params []string
+	// Required is the keys that are required
+	Required []string
+	// Validate is an optional extra validation
+	Validate func(*Annotation) error
+}
+
+// AnnotationSchema is the list of the known annotations. the validation is base on it
+type AnnotationSchema struct {
+	Rules map[string]*AnnotationRule
+	// Strict means the unknown annotations are error too
+	Strict bool
+}
+
+// NewAnnotationSchema return an empty schema
+func NewAnnotationSchema(strict bool) *AnnotationSchema {
+	return &AnnotationSchema{Rules: make(map[string]*AnnotationRule), Strict: strict}
+}
+
+// Register add a rule for an annotation name
+func (s *AnnotationSchema) Register(name string, r *AnnotationRule) {
+	s.Rules[name] = r
+}
+
+func (s *AnnotationSchema) check(a *Annotation) error {
+	r, ok := s.Rules[a.Name]
+	if !ok {
+		if s.Strict {
+			return fmt.Errorf("unknown annotation @%s", a.Name)
+		}
+		return nil
+	}
+	if len(a.Args) < r.MinArgs {
+		return fmt.Errorf("annotation @%s needs at least %d argument", a.Name, r.MinArgs)
+	}
+	if r.MaxArgs >= 0 && len(a.Args) > r.MaxArgs {
+		return fmt.Errorf("annotation @%s accept at most %d argument", a.Name, r.MaxArgs)
+	}
+	if r.Params != nil {
+	bigLoop:
+		for k := range a.Params {
+			for i := range r.Params {
+				if r.Params[i] == k {
+					continue bigLoop
+				}
+			}
+			return fmt.Errorf("annotation @%s has unknown key %s", a.Name, k)
+		}
+	}
+	for _, k := range r.Required {
+		if _, ok := a.Params[k]; !ok {
+			return fmt.Errorf("annotation @%s needs the key %s", a.Name, k)
+		}
+	}
+	if r.Validate != nil {
+		return r.Validate(a)
+	}
+	return nil
+}
+
+// Validate check all the annotations against the schema
+func (s *AnnotationSchema) Validate(a Annotations) []Diagnostic {
+	var res []Diagnostic
+	for i := range a {
+		if err := s.check(a[i]); err != nil {
+			res = append(res, Diagnostic{Pos: a[i].Pos, Message: err.Error()})
+		}
+	}
+	return res
+}
+
+func (s *AnnotationSchema) validateType(t Type) []Diagnostic {
+	var res []Diagnostic
+	switch x := t.(type) {
+	case *StructType:
+		for _, f := range x.Fields {
+			res = append(res, s.Validate(f.Annotations)...)
+		}
+	case *InterfaceType:
+		for _, fn := range x.Functions {
+			res = append(res, s.Validate(fn.Annotations)...)
+		}
+	}
+	return res
+}
+
+// ValidatePackage check all the annotations in the package against the schema
+func (s *AnnotationSchema) ValidatePackage(p *Package) []Diagnostic {
+	var res []Diagnostic
+	for _, f := range p.Files {
+		res = append(res, s.Validate(f.Annotations)...)
+		for _, fn := range f.Functions {
+			res = append(res, s.Validate(fn.Annotations)...)
+		}
+		for _, t := range f.Types {
+			res = append(res, s.Validate(t.Annotations)...)
+			res = append(res, s.validateType(t.Type)...)
+		}
+		for _, v := range f.Variables {
+			res = append(res, s.Validate(v.Annotations)...)
+		}
+		for _, c := range f.Constants {
+			res = append(res, s.Validate(c.Annotations)...)
+		}
+	}
+	return res
+}
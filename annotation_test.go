@@ -0,0 +1,130 @@
+package humanize
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+var ann = `// @Module users
+package test
+
+// Handler is the handler
+// @Route GET /users/{id} auth=true name="get user"
+// @Cache
+type Handler struct {
+	// @Validate required max=10
+	Name string
+}
+
+// @Inject
+var db int
+
+/*
+ @Const "a = b"
+*/
+const x = 1
+
+//AnnotatedOne is anotated one
+// @Test is test dude "one liner"
+// contact me@example.com
+func AnnotatedOne() {
+}
+
+// @Broken "unterminated
+func Broken() {
+}
+`
+
+func TestAnnotation(t *testing.T) {
+	Convey("Annotation test", t, func() {
+		var p = &Package{}
+		f, err := parseFile("ann.go", ann, p)
+		So(err, ShouldBeNil)
+		p.Files = append(p.Files, f)
+
+		Convey("parse", func() {
+			So(len(f.Annotations), ShouldEqual, 1)
+			So(f.Annotations[0].Name, ShouldEqual, "Module")
+			So(f.Annotations[0].Args, ShouldResemble, []string{"users"})
+
+			fn, err := p.FindFunction("AnnotatedOne")
+			So(err, ShouldBeNil)
+			So(len(fn.Annotations), ShouldEqual, 1)
+			a, ok := fn.Annotations.Find("Test")
+			So(ok, ShouldBeTrue)
+			So(a.Args, ShouldResemble, []string{"is", "test", "dude", "one liner"})
+			So(a.Pos.Filename, ShouldEqual, "ann.go")
+			So(a.Pos.Line, ShouldEqual, 21)
+			So(a.Pos.Column, ShouldEqual, 4)
+
+			tn, err := p.FindType("Handler")
+			So(err, ShouldBeNil)
+			So(len(tn.Annotations), ShouldEqual, 2)
+			a, ok = tn.Annotations.Find("Route")
+			So(ok, ShouldBeTrue)
+			So(a.Args, ShouldResemble, []string{"GET", "/users/{id}"})
+			v, ok := a.Param("name")
+			So(ok, ShouldBeTrue)
+			So(v, ShouldEqual, "get user")
+			So(a.Params["auth"], ShouldEqual, "true")
+			_, ok = tn.Annotations.Find("Missing")
+			So(ok, ShouldBeFalse)
+
+			fa := tn.Type.(*StructType).Fields[0].Annotations
+			So(len(fa), ShouldEqual, 1)
+			So(fa[0].Name, ShouldEqual, "Validate")
+			So(fa[0].Params["max"], ShouldEqual, "10")
+
+			vr, err := p.FindVariable("db")
+			So(err, ShouldBeNil)
+			So(len(vr.Annotations.FindAll("Inject")), ShouldEqual, 1)
+
+			c, err := p.FindConstant("x")
+			So(err, ShouldBeNil)
+			So(c.Annotations[0].Args, ShouldResemble, []string{"a = b"})
+			So(c.Annotations[0].Pos.Line, ShouldEqual, 16)
+
+			So(len(p.Diagnostics), ShouldEqual, 1)
+			So(p.Diagnostics[0].Pos.Line, ShouldEqual, 26)
+		})
+
+		Convey("quoted", func() {
+			a, err := parseAnnotation(`@A "a=b" x`)
+			So(err, ShouldBeNil)
+			So(a.Args, ShouldResemble, []string{"a=b", "x"})
+			So(a.Params, ShouldBeEmpty)
+
+			a, err = parseAnnotation(`@A key="va=lue" "k"=v`)
+			So(err, ShouldBeNil)
+			So(a.Params, ShouldResemble, map[string]string{"key": "va=lue"})
+			So(a.Args, ShouldResemble, []string{"k=v"})
+		})
+
+		Convey("schema", func() {
+			s := NewAnnotationSchema(true)
+			s.Register("Module", &AnnotationRule{MinArgs: 1, MaxArgs: 1})
+			s.Register("Route", &AnnotationRule{MinArgs: 2, MaxArgs: 2, Params: []string{"auth"}})
+			s.Register("Cache", &AnnotationRule{MaxArgs: -1, Required: []string{"ttl"}})
+			s.Register("Validate", &AnnotationRule{MaxArgs: -1, Validate: func(a *Annotation) error {
+				return fmt.Errorf("invalid %s", a.Name)
+			}})
+			s.Register("Inject", &AnnotationRule{})
+			s.Register("Const", &AnnotationRule{MaxArgs: 0})
+
+			d := s.ValidatePackage(p)
+			var msgs []string
+			for i := range d {
+				msgs = append(msgs, d[i].String())
+			}
+			So(msgs, ShouldResemble, []string{
+				"ann.go:21:4: unknown annotation @Test",
+				"ann.go:5:4: annotation @Route has unknown key name",
+				"ann.go:6:4: annotation @Cache needs the key ttl",
+				"ann.go:8:5: invalid Validate",
+				"ann.go:16:2: annotation @Const accept at most 0 argument",
+			})
+		})
+	})
+}
@@ -0,0 +1,320 @@
+package humanize
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+)
+
+// Body is the summary of a function body
+type Body struct {
+	Source   string
+	Pos, End token.Position
+	// Locals is the local variables, declared with var, := or range. the type is
+	// nil if it can not be inferred
+	Locals []*Variable
+	// Returns is the return statements of the function, not the function literals
+	Returns []*Return
+	// Calls is all the calls in the body, in the source order, the calls inside the
+	// function literals are in the literal body
+	Calls []*CallSite
+	// Literals is the function literals in the body, the name is the function name
+	// with .funcN suffix
+	Literals []*Function
+}
+
+// Return is a single return statement
+type Return struct {
+	Source  string
+	Pos     token.Position
+	Results []*Expression
+}
+
+// CallSite is a function or method call in a function body
+type CallSite struct {
+	// Name is the callee as written in the source, like fmt.Println or x.Close
+	Name   string
+	Source string
+	Pos    token.Position
+	Args   []*Expression
+	// Builtin is true for the calls to the builtin functions like len
+	Builtin bool
+	// Function is the callee, it is nil if it can not be resolved, like function values.
+	// for the interface methods, it is the method in the interface
+	Function *Function
+	// Package is the package of the callee, if it is resolved
+	Package *Package
+	// Interface is the interface type, if the callee is an interface method
+	Interface *InterfaceType
+}
+
+var builtinFuncs = map[string]bool{
+	"append": true, "cap": true, "clear": true, "close": true, "complex": true, "copy": true,
+	"delete": true, "imag": true, "len": true, "make": true, "max": true, "min": true,
+	"new": true, "panic": true, "print": true, "println": true, "real": true, "recover": true,
+}
+
+// bodyWalker collect the body data, the scope is flat, the shadowed variables in
+// the inner blocks are not handled
+type bodyWalker struct {
+	et   *exprTyper
+	fn   *Function
+	body *Body
+}
+
+// Body return the summary of the function body, it is computed on the first call. it
+// is nil for the functions without body
+func (fn *Function) Body() *Body {
+	if fn.body != nil || fn.file == nil {
+		return fn.body
+	}
+	var block *ast.BlockStmt
+	switch {
+	case fn.decl != nil:
+		block = fn.decl.Body
+	case fn.lit != nil:
+		// the package level function literals
+		block = fn.lit.Body
+	}
+	if block == nil {
+		return nil
+	}
+	et := newTyper(fn.pkg, fn.file)
+	et.locals = make(map[string]Type)
+	et.localTypes = make(map[string]*TypeName)
+	for _, tn := range fn.Types {
+		et.localTypes[tn.Name] = tn
+	}
+	if fn.Receiver != nil && fn.Receiver.Name != "" {
+		et.locals[fn.Receiver.Name] = fn.Receiver.Type
+	}
+	for _, v := range append(fn.Type.Parameters, fn.Type.Results...) {
+		if v.Name != "" {
+			et.locals[v.Name] = v.Type
+		}
+	}
+
+	walkBody(et, fn, block)
+	return fn.body
+}
+
+// walkBody fill the body of the function, the locals of the parent functions are
+// in the typer
+func walkBody(et *exprTyper, fn *Function, b *ast.BlockStmt) {
+	fn.body = &Body{
+		Source: getSource(b, et.file.src),
+		Pos:    et.file.position(b.Pos()),
+		End:    et.file.position(b.End()),
+	}
+	ast.Walk(&bodyWalker{et: et, fn: fn, body: fn.body}, b)
+}
+
+func (bw *bodyWalker) addLocal(name string, t Type) {
+	if name == "_" || name == "" {
+		return
+	}
+	if _, ok := bw.et.locals[name]; ok && t == nil {
+		return
+	}
+	bw.et.locals[name] = t
+	bw.body.Locals = append(bw.body.Locals, &Variable{Name: name, Type: t})
+}
+
+func (bw *bodyWalker) typeOf(e ast.Expr, indx, count int) Type {
+	var (
+		t   Type
+		err error
+	)
+	if count > 1 {
+		t, err = bw.et.typeOfIndex(e, indx)
+	} else {
+		t, err = bw.et.typeOf(e)
+	}
+	if err != nil {
+		return nil
+	}
+	return t
+}
+
+func (bw *bodyWalker) valueSpec(v *ast.ValueSpec) {
+	for i, n := range v.Names {
+		var t Type
+		switch {
+		case v.Type != nil:
+			t = getType(v.Type, bw.et.file.src, bw.et.file, bw.et.pkg)
+		case len(v.Values) == 1 && len(v.Names) > 1:
+			t = bw.typeOf(v.Values[0], i, len(v.Names))
+		case i < len(v.Values):
+			t = bw.typeOf(v.Values[i], 0, 1)
+		}
+		bw.addLocal(nameFromIdent(n), t)
+	}
+}
+
+func (bw *bodyWalker) assign(a *ast.AssignStmt) {
+	for i := range a.Lhs {
+		id, ok := a.Lhs[i].(*ast.Ident)
+		if !ok {
+			continue
+		}
+		// the := redeclare the old ones
+		if _, ok := bw.et.locals[id.Name]; ok {
+			continue
+		}
+		var t Type
+		if len(a.Rhs) == 1 && len(a.Lhs) > 1 {
+			t = bw.typeOf(a.Rhs[0], i, len(a.Lhs))
+		} else if i < len(a.Rhs) {
+			t = bw.typeOf(a.Rhs[i], 0, 1)
+		}
+		bw.addLocal(id.Name, t)
+	}
+}
+
+func (bw *bodyWalker) rangeStmt(r *ast.RangeStmt) {
+	var key, value Type
+	if x, err := bw.et.typeOf(r.X); err == nil {
+		u := bw.et.underlying(x)
+		if st, ok := u.(*StarType); ok {
+			u = bw.et.underlying(st.Target)
+		}
+		switch c := u.(type) {
+		case *ArrayType:
+			key, value = bw.et.ident("int"), c.Type
+		case *EllipsisType:
+			key, value = bw.et.ident("int"), c.Type
+		case *MapType:
+			key, value = c.Key, c.Value
+		case *ChannelType:
+			key = c.Type
+		case *IdentType:
+			switch {
+			case c.Ident == "string":
+				key, value = bw.et.ident("int"), bw.et.ident("rune")
+			case predeclared[c.Ident]:
+				key = c
+			}
+		}
+	}
+	if id, ok := r.Key.(*ast.Ident); ok {
+		bw.addLocal(id.Name, key)
+	}
+	if id, ok := r.Value.(*ast.Ident); ok {
+		bw.addLocal(id.Name, value)
+	}
+}
+
+// resolve find the callee function of the call
+func (bw *bodyWalker) resolve(cs *CallSite, fun ast.Expr) {
+	et := bw.et
+	switch t := fun.(type) {
+	case *ast.ParenExpr:
+		bw.resolve(cs, t.X)
+	case *ast.Ident:
+		if et.inScope(t.Name) {
+			return
+		}
+		if fn, err := et.pkg.FindFunction(t.Name); err == nil {
+			cs.Function, cs.Package = fn, et.pkg
+			return
+		}
+		cs.Builtin = builtinFuncs[t.Name]
+	case *ast.SelectorExpr:
+		sel := nameFromIdent(t.Sel)
+		if id, ok := t.X.(*ast.Ident); ok && !et.inScope(id.Name) {
+			if imp := getImport(id.Name, et.file); imp != nil {
+				if pkg, err := ParsePackage(imp.Path); err == nil {
+					if fn, err := pkg.FindFunction(sel); err == nil {
+						cs.Function, cs.Package = fn, pkg
+					}
+				}
+				return
+			}
+		}
+		x, err := et.typeOf(t.X)
+		if err != nil {
+			return
+		}
+		cs.Function, cs.Package, cs.Interface = et.method(x, sel, 0)
+	}
+}
+
+func (bw *bodyWalker) call(c *ast.CallExpr) {
+	et := bw.et
+	// type conversions are not calls
+	if et.typeExpr(c.Fun) != nil {
+		if id, ok := c.Fun.(*ast.Ident); !ok || !et.inScope(id.Name) {
+			return
+		}
+	}
+	src := et.file.src
+	cs := &CallSite{
+		Name:   getSource(c.Fun, src),
+		Source: getSource(c, src),
+		Pos:    et.file.position(c.Pos()),
+	}
+	for i := range c.Args {
+		cs.Args = append(cs.Args, newExpression(c.Args[i], src, et.file, et.pkg))
+	}
+	bw.resolve(cs, c.Fun)
+	bw.body.Calls = append(bw.body.Calls, cs)
+}
+
+// literal add the function literal, with its own body. the literal can use the
+// locals of the parent
+func (bw *bodyWalker) literal(l *ast.FuncLit) {
+	et := bw.et
+	lit := &Function{
+		Name: fmt.Sprintf("%s.func%d", bw.fn.Name, len(bw.body.Literals)+1),
+		Type: getType(l.Type, et.file.src, et.file, et.pkg).(*FuncType),
+		lit:  l,
+		file: et.file,
+		pkg:  et.pkg,
+	}
+	bw.body.Literals = append(bw.body.Literals, lit)
+	for _, v := range append(lit.Type.Parameters, lit.Type.Results...) {
+		if v.Name != "" {
+			et.locals[v.Name] = v.Type
+		}
+	}
+	walkBody(et, lit, l.Body)
+}
+
+func (bw *bodyWalker) Visit(node ast.Node) ast.Visitor {
+	switch t := node.(type) {
+	case *ast.FuncLit:
+		bw.literal(t)
+		return nil
+	case *ast.DeclStmt:
+		if gd, ok := t.Decl.(*ast.GenDecl); ok && gd.Tok == token.VAR {
+			for _, s := range gd.Specs {
+				bw.valueSpec(s.(*ast.ValueSpec))
+			}
+		}
+	case *ast.AssignStmt:
+		if t.Tok == token.DEFINE {
+			// the right side first, the new variables are not in the scope yet
+			for i := range t.Rhs {
+				ast.Walk(bw, t.Rhs[i])
+			}
+			bw.assign(t)
+			return nil
+		}
+	case *ast.RangeStmt:
+		if t.Tok == token.DEFINE {
+			ast.Walk(bw, t.X)
+			bw.rangeStmt(t)
+			ast.Walk(bw, t.Body)
+			return nil
+		}
+	case *ast.ReturnStmt:
+		r := &Return{Source: getSource(t, bw.et.file.src), Pos: bw.et.file.position(t.Pos())}
+		for i := range t.Results {
+			r.Results = append(r.Results, newExpression(t.Results[i], bw.et.file.src, bw.et.file, bw.et.pkg))
+		}
+		bw.body.Returns = append(bw.body.Returns, r)
+	case *ast.CallExpr:
+		bw.call(t)
+	}
+	return bw
+}
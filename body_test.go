@@ -0,0 +1,235 @@
+package humanize
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+var bodySrc = `package test
+
+import (
+	"strings"
+)
+
+type Closer interface {
+	Close() error
+}
+
+type Base struct{}
+
+func (b *Base) Close() error {
+	return nil
+}
+
+type Server struct {
+	Base
+	name string
+}
+
+func (s Server) Name() string {
+	return s.name
+}
+
+func helper(a int, rest ...string) (int, error) {
+	return a + len(rest), nil
+}
+
+var global = func() {}
+
+func (s *Server) Run(c Closer) (err error) {
+	var count int
+	x, err := helper(count, "a", "b")
+	if x > 10 {
+		return s.Close()
+	}
+	name := strings.ToUpper(s.Name())
+	for i, ch := range name {
+		_ = i
+		_ = ch
+	}
+	fn := func(v int) int {
+		return v * x
+	}
+	_ = int64(fn(x))
+	global()
+	c.Close()
+	return
+}
+
+func NoBody() int
+`
+
+func TestBody(t *testing.T) {
+	Convey("Body test", t, func() {
+		var p = &Package{}
+		f, err := ParseFile(bodySrc, p)
+		So(err, ShouldBeNil)
+		p.Files = append(p.Files, f)
+		So(lateBind(p), ShouldBeNil)
+
+		fn, err := p.FindFunction("Server.Run")
+		So(err, ShouldBeNil)
+		b := fn.Body()
+		So(b, ShouldNotBeNil)
+		So(fn.Body(), ShouldEqual, b)
+		So(b.Source, ShouldStartWith, "{\n\tvar count int")
+		So(b.Pos.Line, ShouldEqual, 32)
+		So(b.End.Line, ShouldEqual, 50)
+
+		Convey("locals", func() {
+			var names, types []string
+			for _, v := range b.Locals {
+				names = append(names, v.Name)
+				if v.Type == nil {
+					types = append(types, "")
+				} else {
+					types = append(types, v.Type.GetDefinition())
+				}
+			}
+			So(names, ShouldResemble, []string{"count", "x", "name", "i", "ch", "fn"})
+			So(types, ShouldResemble, []string{"int", "int", "string", "int", "rune", "func (int) int"})
+		})
+
+		Convey("returns", func() {
+			So(len(b.Returns), ShouldEqual, 2)
+			So(b.Returns[0].Source, ShouldEqual, "return s.Close()")
+			So(b.Returns[0].Results[0].Kind, ShouldEqual, CallExpression)
+			So(b.Returns[0].Pos.Line, ShouldEqual, 36)
+			So(len(b.Returns[1].Results), ShouldEqual, 0)
+		})
+
+		Convey("calls", func() {
+			var names []string
+			for _, c := range b.Calls {
+				names = append(names, c.Name)
+			}
+			So(names, ShouldResemble, []string{"helper", "s.Close", "strings.ToUpper", "s.Name", "fn", "global", "c.Close"})
+
+			So(b.Calls[0].Function.Name, ShouldEqual, "helper")
+			So(b.Calls[0].Package, ShouldEqual, p)
+			So(len(b.Calls[0].Args), ShouldEqual, 3)
+			So(b.Calls[0].Args[1].Value, ShouldEqual, `"a"`)
+
+			// promoted from the embeded type
+			So(b.Calls[1].Function.Name, ShouldEqual, "Base.Close")
+
+			So(b.Calls[2].Function, ShouldNotBeNil)
+			So(b.Calls[2].Function.Name, ShouldEqual, "ToUpper")
+			So(b.Calls[2].Package.Path, ShouldEqual, "strings")
+
+			So(b.Calls[3].Function.Name, ShouldEqual, "Server.Name")
+			So(b.Calls[4].Function, ShouldBeNil)
+			So(b.Calls[5].Function, ShouldBeNil)
+
+			// interface method
+			So(b.Calls[6].Function.Name, ShouldEqual, "Close")
+			So(b.Calls[6].Pos.Line, ShouldEqual, 48)
+		})
+
+		Convey("literals", func() {
+			So(len(b.Literals), ShouldEqual, 1)
+			lit := b.Literals[0]
+			So(lit.Name, ShouldEqual, "Server.Run.func1")
+			So(lit.Type.GetDefinition(), ShouldEqual, "func (int) int")
+			So(lit.Body(), ShouldNotBeNil)
+			So(len(lit.Body().Returns), ShouldEqual, 1)
+			So(lit.Body().Returns[0].Source, ShouldEqual, "return v * x")
+		})
+
+		Convey("no body", func() {
+			fn, err := p.FindFunction("NoBody")
+			So(err, ShouldBeNil)
+			So(fn.Body(), ShouldBeNil)
+
+			fn, err = p.FindFunction("helper")
+			So(err, ShouldBeNil)
+			b := fn.Body()
+			So(len(b.Calls), ShouldEqual, 1)
+			So(b.Calls[0].Builtin, ShouldBeTrue)
+		})
+	})
+}
+
+var localSrc = `package test
+
+type ctx struct{}
+
+var handlers = map[string]func(c ctx) error{
+	"ping": func(c ctx) error {
+		type pong struct{ Msg string }
+		_ = pong{"pong"}
+		return nil
+	},
+	"stop": func(ctx) error { return stop() },
+}
+
+func stop() error { return nil }
+
+type Base struct{}
+
+func (Base) Hello() string { return "" }
+
+func Local() string {
+	type inner struct {
+		Base
+		Name string
+	}
+	go func() {
+		type deep int
+	}()
+	var x inner
+	return x.Hello()
+}
+`
+
+func TestLocal(t *testing.T) {
+	Convey("Local declarations test", t, func() {
+		var p = &Package{Path: "example.com/local"}
+		f, err := ParseFile(localSrc, p)
+		So(err, ShouldBeNil)
+		p.Files = append(p.Files, f)
+		So(lateBind(p), ShouldBeNil)
+
+		Convey("local types", func() {
+			fn, err := p.FindFunction("Local")
+			So(err, ShouldBeNil)
+			So(len(fn.Types), ShouldEqual, 2)
+			So(fn.Types[0].Name, ShouldEqual, "inner")
+			So(fn.Types[0].Scope, ShouldEqual, fn)
+			So(len(fn.Types[0].Type.(*StructType).Fields), ShouldEqual, 1)
+			So(fn.Types[1].Name, ShouldEqual, "deep")
+			So(fn.Types[1].Scope, ShouldEqual, fn)
+
+			// not in the package scope
+			_, err = p.FindType("inner")
+			So(err, ShouldNotBeNil)
+
+			b := fn.Body()
+			So(b.Locals[0].Type.GetDefinition(), ShouldEqual, "inner")
+			So(b.Calls[len(b.Calls)-1].Function.Name, ShouldEqual, "Base.Hello")
+		})
+
+		Convey("function literals", func() {
+			v, err := p.FindVariable("handlers")
+			So(err, ShouldBeNil)
+			So(v.Type.GetDefinition(), ShouldEqual, "map[string]func (ctx) error")
+			el := v.Expression.Elements
+			So(len(el), ShouldEqual, 2)
+			ping := el[0].Y.Function
+			So(ping.Name, ShouldEqual, "handlers.func1")
+			So(ping.Type.GetDefinition(), ShouldEqual, "func (ctx) error")
+			So(len(ping.Types), ShouldEqual, 1)
+			So(ping.Types[0].Name, ShouldEqual, "pong")
+			So(len(ping.Body().Returns), ShouldEqual, 1)
+
+			stop := el[1].Y.Function
+			So(stop.Name, ShouldEqual, "handlers.func2")
+			So(stop.Body().Calls[0].Function.Name, ShouldEqual, "stop")
+
+			g := NewCallGraph(p)
+			So(names(g.EntryPoints()), ShouldResemble, []string{"Base.Hello", "Local", "handlers.func1", "handlers.func2"})
+			So(names(g.Unreachable()), ShouldBeEmpty)
+		})
+	})
+}
@@ -0,0 +1,314 @@
+package humanize
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"io"
+	"strconv"
+)
+
+// CallGraph is the static call graph of a set of packages
+type CallGraph struct {
+	Nodes []*CallNode
+	Edges []*CallEdge
+
+	pkgs  map[string]*Package
+	nodes map[*Function]*CallNode
+	impls map[*InterfaceType][]*TypeName
+	// varLits is the function literals in the package variables
+	varLits map[*Function]bool
+}
+
+// CallNode is a single function in the call graph
+type CallNode struct {
+	// ID is the package path and the function name, like the fmt.Println
+	ID       string
+	Function *Function
+	Package  *Package
+	// External is true for the functions that are not in the graph packages, their
+	// calls are not in the graph
+	External bool
+	In, Out  []*CallEdge
+}
+
+// CallEdge is a call from a function to another one
+type CallEdge struct {
+	Caller, Callee *CallNode
+	// Site is the call, it is nil for the edges from a function to its literals
+	Site *CallSite
+	// Dynamic is true for the interface method calls resolved to the implementations
+	Dynamic bool
+}
+
+// NewCallGraph create the call graph of the packages, the calls to the other packages
+// are the external nodes
+func NewCallGraph(pkgs ...*Package) *CallGraph {
+	g := &CallGraph{
+		pkgs:  make(map[string]*Package),
+		nodes: make(map[*Function]*CallNode),
+		impls: make(map[*InterfaceType][]*TypeName),
+
+		varLits: make(map[*Function]bool),
+	}
+	for _, p := range pkgs {
+		g.pkgs[p.Path] = p
+		findMethods(p)
+	}
+	for _, p := range pkgs {
+		for _, f := range p.Files {
+			for _, fn := range f.Functions {
+				g.walk(g.node(fn, p, nil))
+			}
+			for _, v := range f.Variables {
+				for _, lit := range v.Expression.literals() {
+					g.varLits[lit] = true
+					g.walk(g.node(lit, p, nil))
+				}
+			}
+		}
+	}
+	return g
+}
+
+func (g *CallGraph) node(fn *Function, p *Package, in *InterfaceType) *CallNode {
+	if n, ok := g.nodes[fn]; ok {
+		return n
+	}
+	n := &CallNode{Function: fn, Package: p, ID: fn.Name}
+	if in != nil {
+		n.ID = interfaceName(in, p) + "." + fn.Name
+	}
+	if p != nil {
+		n.ID = p.Path + "." + n.ID
+		_, ok := g.pkgs[p.Path]
+		n.External = !ok
+	}
+	g.nodes[fn] = n
+	g.Nodes = append(g.Nodes, n)
+	return n
+}
+
+func interfaceName(in *InterfaceType, p *Package) string {
+	if p != nil {
+		for _, f := range p.Files {
+			for _, tn := range f.Types {
+				if tn.Type == in {
+					return tn.Name
+				}
+			}
+		}
+	}
+	return "interface"
+}
+
+func (g *CallGraph) edge(caller, callee *CallNode, site *CallSite, dynamic bool) {
+	e := &CallEdge{Caller: caller, Callee: callee, Site: site, Dynamic: dynamic}
+	caller.Out = append(caller.Out, e)
+	callee.In = append(callee.In, e)
+	g.Edges = append(g.Edges, e)
+}
+
+func (g *CallGraph) walk(n *CallNode) {
+	b := n.Function.Body()
+	if b == nil {
+		return
+	}
+	for _, lit := range b.Literals {
+		ln := g.node(lit, n.Package, nil)
+		g.edge(n, ln, nil, false)
+		g.walk(ln)
+	}
+	for _, c := range b.Calls {
+		if c.Function == nil {
+			continue
+		}
+		g.edge(n, g.node(c.Function, c.Package, c.Interface), c, false)
+		if c.Interface == nil {
+			continue
+		}
+		for _, impl := range g.implementations(c.Interface, c.Function.Name) {
+			g.edge(n, g.node(impl, impl.pkg, nil), c, true)
+		}
+	}
+}
+
+// supports is the TypeName.Support with the pointer receiver, the method set of the
+// addressable values
+func supports(tn *TypeName, in *InterfaceType) bool {
+	return tn.Support(in, true)
+}
+
+// implementations return the methods of the types in the graph packages that
+// implement the interface
+func (g *CallGraph) implementations(in *InterfaceType, name string) []*Function {
+	tns, ok := g.impls[in]
+	if !ok {
+		for _, p := range g.pkgs {
+			for _, f := range p.Files {
+				for _, tn := range f.Types {
+					if _, iface := tn.Type.(*InterfaceType); !iface && supports(tn, in) {
+						tns = append(tns, tn)
+					}
+				}
+			}
+		}
+		g.impls[in] = tns
+	}
+
+	var res []*Function
+	for _, tn := range tns {
+		for _, fn := range append(tn.Methods, tn.StarMethods...) {
+			if removeReceiver(fn.Name) == name {
+				res = append(res, fn)
+			}
+		}
+	}
+	return res
+}
+
+// EntryPoints return the main and init functions and the exported API of the graph
+// packages. the function literals in the package variables are entry points too,
+// they are values that can be called from anywhere
+func (g *CallGraph) EntryPoints() []*Function {
+	var res []*Function
+	for _, n := range g.Nodes {
+		fn := n.Function
+		if n.External {
+			continue
+		}
+		if g.varLits[fn] {
+			res = append(res, fn)
+			continue
+		}
+		if fn.decl == nil {
+			continue
+		}
+		switch {
+		case fn.Name == "init" || (fn.Name == "main" && n.Package.Name == "main"):
+			res = append(res, fn)
+		case fn.Receiver == nil && ast.IsExported(fn.Name):
+			res = append(res, fn)
+		case fn.Receiver != nil && ast.IsExported(removeStar(fn.Receiver.Type.GetDefinition())) && ast.IsExported(removeReceiver(fn.Name)):
+			res = append(res, fn)
+		}
+	}
+	return res
+}
+
+// Reachable return all the functions that are reachable from the roots, the roots are
+// included
+func (g *CallGraph) Reachable(roots ...*Function) []*Function {
+	seen := make(map[*CallNode]bool)
+	var (
+		res   []*Function
+		queue []*CallNode
+	)
+	for _, r := range roots {
+		if n, ok := g.nodes[r]; ok && !seen[n] {
+			seen[n] = true
+			queue = append(queue, n)
+		}
+	}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		res = append(res, n.Function)
+		for _, e := range n.Out {
+			if !seen[e.Callee] {
+				seen[e.Callee] = true
+				queue = append(queue, e.Callee)
+			}
+		}
+	}
+	return res
+}
+
+// Unreachable return the functions of the graph packages that are not reachable from
+// the entry points, the dead code
+func (g *CallGraph) Unreachable() []*Function {
+	live := make(map[*Function]bool)
+	for _, fn := range g.Reachable(g.EntryPoints()...) {
+		live[fn] = true
+	}
+	var res []*Function
+	for _, n := range g.Nodes {
+		// the interface methods has no code
+		if n.External || live[n.Function] || (n.Function.decl == nil && n.Function.lit == nil) {
+			continue
+		}
+		res = append(res, n.Function)
+	}
+	return res
+}
+
+// WriteDOT write the graph in the graphviz dot format, the external nodes and the
+// dynamic calls are dashed
+func (g *CallGraph) WriteDOT(w io.Writer) error {
+	buf := &bytes.Buffer{}
+	buf.WriteString("digraph callgraph {\n")
+	for _, n := range g.Nodes {
+		attr := ""
+		if n.External {
+			attr = ", style=dashed"
+		}
+		fmt.Fprintf(buf, "\t%s [label=%s%s];\n", strconv.Quote(n.ID), strconv.Quote(n.ID), attr)
+	}
+	seen := make(map[[2]*CallNode]bool)
+	for _, e := range g.Edges {
+		key := [2]*CallNode{e.Caller, e.Callee}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		attr := ""
+		if e.Dynamic {
+			attr = " [style=dashed]"
+		}
+		fmt.Fprintf(buf, "\t%s -> %s%s;\n", strconv.Quote(e.Caller.ID), strconv.Quote(e.Callee.ID), attr)
+	}
+	buf.WriteString("}\n")
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+type jsonCallNode struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Package  string `json:"package,omitempty"`
+	External bool   `json:"external,omitempty"`
+}
+
+type jsonCallEdge struct {
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Dynamic  bool   `json:"dynamic,omitempty"`
+	Position string `json:"position,omitempty"`
+}
+
+// MarshalJSON return the graph as a list of nodes and edges
+func (g *CallGraph) MarshalJSON() ([]byte, error) {
+	res := struct {
+		Nodes []jsonCallNode `json:"nodes"`
+		Edges []jsonCallEdge `json:"edges"`
+	}{
+		Nodes: []jsonCallNode{},
+		Edges: []jsonCallEdge{},
+	}
+	for _, n := range g.Nodes {
+		jn := jsonCallNode{ID: n.ID, Name: n.Function.Name, External: n.External}
+		if n.Package != nil {
+			jn.Package = n.Package.Path
+		}
+		res.Nodes = append(res.Nodes, jn)
+	}
+	for _, e := range g.Edges {
+		je := jsonCallEdge{From: e.Caller.ID, To: e.Callee.ID, Dynamic: e.Dynamic}
+		if e.Site != nil && e.Site.Pos.IsValid() {
+			je.Position = e.Site.Pos.String()
+		}
+		res.Edges = append(res.Edges, je)
+	}
+	return json.Marshal(res)
+}
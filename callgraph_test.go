@@ -0,0 +1,125 @@
+package humanize
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+var callGraphSrc = `package main
+
+import "strings"
+
+type Shape interface {
+	Area() int
+}
+
+type Square struct{ side int }
+
+func (s Square) Area() int { return s.side * s.side }
+
+type Circle struct{ r int }
+
+func (c *Circle) Area() int { return 3 * c.r * c.r }
+
+func total(shapes []Shape) int {
+	res := 0
+	for _, s := range shapes {
+		res += s.Area()
+	}
+	return res
+}
+
+func main() {
+	run := func() {
+		println(total([]Shape{Square{2}, &Circle{1}}))
+	}
+	run()
+	_ = strings.TrimSpace(" x ")
+}
+
+func dead() {
+	deader()
+}
+
+func deader() {}
+`
+
+func names(fns []*Function) []string {
+	var res []string
+	for _, fn := range fns {
+		res = append(res, fn.Name)
+	}
+	return res
+}
+
+func TestCallGraph(t *testing.T) {
+	Convey("Call graph test", t, func() {
+		var p = &Package{Path: "example.com/shapes", Name: "main"}
+		f, err := ParseFile(callGraphSrc, p)
+		So(err, ShouldBeNil)
+		p.Files = append(p.Files, f)
+		So(lateBind(p), ShouldBeNil)
+
+		g := NewCallGraph(p)
+
+		Convey("nodes and edges", func() {
+			var ids []string
+			for _, n := range g.Nodes {
+				ids = append(ids, n.ID)
+			}
+			So(ids, ShouldResemble, []string{
+				"example.com/shapes.Square.Area",
+				"example.com/shapes.Circle.Area",
+				"example.com/shapes.total",
+				"example.com/shapes.Shape.Area",
+				"example.com/shapes.main",
+				"example.com/shapes.main.func1",
+				"strings.TrimSpace",
+				"example.com/shapes.dead",
+				"example.com/shapes.deader",
+			})
+			total := g.Nodes[2]
+			So(len(total.Out), ShouldEqual, 3)
+			So(total.Out[0].Dynamic, ShouldBeFalse)
+			So(total.Out[1].Dynamic, ShouldBeTrue)
+			So(total.Out[1].Callee.ID, ShouldEqual, "example.com/shapes.Square.Area")
+			So(total.Out[2].Callee.ID, ShouldEqual, "example.com/shapes.Circle.Area")
+			So(g.Nodes[6].External, ShouldBeTrue)
+		})
+
+		Convey("reachability", func() {
+			So(names(g.EntryPoints()), ShouldResemble, []string{"Square.Area", "Circle.Area", "main"})
+			main, err := p.FindFunction("main")
+			So(err, ShouldBeNil)
+			So(names(g.Reachable(main)), ShouldResemble, []string{
+				"main", "main.func1", "TrimSpace", "total", "Area", "Square.Area", "Circle.Area",
+			})
+			So(names(g.Unreachable()), ShouldResemble, []string{"dead", "deader"})
+		})
+
+		Convey("export", func() {
+			buf := &bytes.Buffer{}
+			So(g.WriteDOT(buf), ShouldBeNil)
+			dot := buf.String()
+			So(dot, ShouldStartWith, "digraph callgraph {\n")
+			So(dot, ShouldContainSubstring, `"strings.TrimSpace" [label="strings.TrimSpace", style=dashed];`)
+			So(dot, ShouldContainSubstring, `"example.com/shapes.total" -> "example.com/shapes.Circle.Area" [style=dashed];`)
+			So(dot, ShouldContainSubstring, `"example.com/shapes.main" -> "example.com/shapes.main.func1";`)
+
+			data, err := json.Marshal(g)
+			So(err, ShouldBeNil)
+			var res struct {
+				Nodes []map[string]interface{}
+				Edges []map[string]interface{}
+			}
+			So(json.Unmarshal(data, &res), ShouldBeNil)
+			So(len(res.Nodes), ShouldEqual, 9)
+			So(len(res.Edges), ShouldEqual, len(g.Edges))
+			So(res.Edges[0]["from"], ShouldEqual, "example.com/shapes.total")
+			So(res.Edges[0]["position"], ShouldEqual, "20:10")
+		})
+	})
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/goraz/humanize"
+)
+
+const genUsage = "gen -t template.tmpl [-t other.tmpl] [-o output.go] [-pkg name] [packages...]"
+
+// gen load the packages and execute the templates, the output is a gofmt-ed go file
+func gen(args []string) error {
+	var templates stringsFlag
+	fs := flag.NewFlagSet("gen", flag.ContinueOnError)
+	fs.Var(&templates, "t", "the template file, the first one is executed and the others can be used as the sub templates")
+	output := fs.String("o", "", "the output file, the default is stdout, or file_template_gen.go in go generate")
+	pkgName := fs.String("pkg", "", "the package name of the output, the default is the first package name")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	// in go generate, the package is the current folder and the target is the
+	// declaration after the directive
+	goFile := os.Getenv("GOFILE")
+	if len(templates) == 0 || (fs.NArg() == 0 && goFile == "") {
+		return fmt.Errorf("usage: humanize %s", genUsage)
+	}
+
+	data := &humanize.GenerateData{}
+	if fs.NArg() == 0 {
+		p, target, err := generateTarget(goFile, os.Getenv("GOLINE"))
+		if err != nil {
+			return err
+		}
+		data.Packages = append(data.Packages, p)
+		data.Target = target
+		if *pkgName == "" {
+			*pkgName = os.Getenv("GOPACKAGE")
+		}
+		if *output == "" {
+			*output = defaultOutput(goFile, templates[0])
+		}
+	}
+	for _, path := range fs.Args() {
+		p, err := humanize.ParsePackage(path)
+		if err != nil {
+			return err
+		}
+		data.Packages = append(data.Packages, p)
+	}
+	data.Package = data.Packages[0]
+
+	g := humanize.NewGenerator("humanize gen", data.Package.Name)
+	if *pkgName != "" && *pkgName != data.Package.Name {
+		g.PackageName = *pkgName
+	} else {
+		// the same package, no need to import it
+		g.Imports.Package = data.Package.Path
+	}
+	if err := g.ParseFiles(templates...); err != nil {
+		return err
+	}
+
+	buf := &bytes.Buffer{}
+	if err := g.Execute(buf, data); err != nil {
+		return err
+	}
+	if *output == "" {
+		_, err := os.Stdout.Write(buf.Bytes())
+		return err
+	}
+	return os.WriteFile(*output, buf.Bytes(), 0644)
+}
+
+// generateTarget load the package in the current folder and find the declaration
+// after the go:generate line
+func generateTarget(goFile, goLine string) (*humanize.Package, interface{}, error) {
+	line, err := strconv.Atoi(goLine)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid GOLINE %q", goLine)
+	}
+	p, err := humanize.ParseDir(".")
+	if err != nil {
+		return nil, nil, err
+	}
+	target, err := p.GenerateTarget(goFile, line)
+	if err != nil {
+		return nil, nil, err
+	}
+	return p, target, nil
+}
+
+// defaultOutput return the output file name for go generate, user.go with the
+// builder.tmpl is user_builder_gen.go
+func defaultOutput(goFile, tmpl string) string {
+	base := strings.TrimSuffix(filepath.Base(tmpl), filepath.Ext(tmpl))
+	return strings.TrimSuffix(goFile, ".go") + "_" + base + "_gen.go"
+}
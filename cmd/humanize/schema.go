@@ -0,0 +1,134 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/goraz/humanize"
+)
+
+const schemaUsage = "schema [-o output] [-input Type] [-methods] <format> <package> [Type...]"
+
+// schemaFormats is the schema formats, each one create the schema of the types in the
+// package
+var schemaFormats = map[string]func(p *humanize.Package, names []string) ([]byte, error){
+	"jsonschema": jsonSchema,
+	"proto":      proto,
+	"typescript": typeScript,
+	"openapi":    openAPI,
+	"graphql":    graphQL,
+}
+
+// graphQLOptions is set by the flags of the schema command
+var graphQLOptions humanize.GraphQLOptions
+
+func jsonSchema(p *humanize.Package, names []string) ([]byte, error) {
+	if len(names) != 1 {
+		return nil, fmt.Errorf("jsonschema needs exactly one type")
+	}
+	s, err := humanize.NewJSONSchema(p, names[0])
+	if err != nil {
+		return nil, err
+	}
+	res, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(res, '\n'), nil
+}
+
+// openAPI create the OpenAPI document of the package, from the annotated functions
+func openAPI(p *humanize.Package, names []string) ([]byte, error) {
+	if len(names) != 0 {
+		return nil, fmt.Errorf("openapi is for the whole package, not the types")
+	}
+	doc, err := humanize.NewOpenAPI(p)
+	if err != nil {
+		return nil, err
+	}
+	res, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(res, '\n'), nil
+}
+
+// graphQL create the GraphQL schema of the types, all the exported types if there
+// is no type
+func graphQL(p *humanize.Package, names []string) ([]byte, error) {
+	res, err := humanize.GraphQL(p, graphQLOptions, names...)
+	return []byte(res), err
+}
+
+// proto create the proto file, the fields that can not be mapped are reported in the
+// stderr
+func proto(p *humanize.Package, names []string) ([]byte, error) {
+	if len(names) == 0 {
+		return nil, fmt.Errorf("proto needs at least one type")
+	}
+	pr, err := humanize.NewProto(p, names...)
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range pr.Diagnostics {
+		fmt.Fprintln(os.Stderr, d)
+	}
+	return []byte(pr.String()), nil
+}
+
+// typeScript create the declarations of the types, all the exported types if there
+// is no type
+func typeScript(p *humanize.Package, names []string) ([]byte, error) {
+	res, err := humanize.TypeScript(p, names...)
+	return []byte(res), err
+}
+
+// loadPackage load the package from the import path, or the folder if it is a
+// relative or absolute path
+func loadPackage(path string) (*humanize.Package, error) {
+	if path == "." || strings.HasPrefix(path, "./") || strings.HasPrefix(path, "../") || strings.HasPrefix(path, "/") {
+		return humanize.ParseDir(path)
+	}
+	return humanize.ParsePackage(path)
+}
+
+// schema export the type in one of the schema formats
+func schema(args []string) error {
+	fs := flag.NewFlagSet("schema", flag.ContinueOnError)
+	output := fs.String("o", "", "the output file, the default is stdout")
+	inputs := (*stringsFlag)(&graphQLOptions.Inputs)
+	fs.Var(inputs, "input", "graphql: the struct is an input type")
+	fs.BoolVar(&graphQLOptions.Methods, "methods", false, "graphql: add the methods without arguments as fields")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() < 2 {
+		var formats []string
+		for f := range schemaFormats {
+			formats = append(formats, f)
+		}
+		sort.Strings(formats)
+		return fmt.Errorf("usage: humanize %s\nformats: %s", schemaUsage, strings.Join(formats, ", "))
+	}
+	format, ok := schemaFormats[fs.Arg(0)]
+	if !ok {
+		return fmt.Errorf("unknown schema format %q", fs.Arg(0))
+	}
+	p, err := loadPackage(fs.Arg(1))
+	if err != nil {
+		return err
+	}
+	res, err := format(p, fs.Args()[2:])
+	if err != nil {
+		return err
+	}
+	if *output == "" {
+		_, err := os.Stdout.Write(res)
+		return err
+	}
+	return os.WriteFile(*output, res, 0644)
+}
@@ -1,7 +1,9 @@
 package humanize
 
 import (
+	"fmt"
 	"go/ast"
+	"go/constant"
 	"go/token"
 )
 
@@ -11,13 +13,32 @@ var (
 
 // Constant is a string represent of a function parameter
 type Constant struct {
-	Name  string
-	Type  Type
-	Docs  Docs
-	Value string
+	Name        string
+	Type        Type
+	Docs        Docs
+	Annotations Annotations
+	Value       string
+	// GroupDocs is the docs of the declaration group, like const ( ... )
+	GroupDocs Docs
+	// SpecDocs is the docs of the declaration itself, Docs is both of them
+	SpecDocs Docs
+	// Comment is the trailing comment, and the inline comments after the name
+	Comment Docs
+	// Initializer is the source of the value, it is empty for the implicit
+	// repetition of the last value in a const group
+	Initializer string
+	// Expression is the structured form of the initializer
+	Expression *Expression
 
-	caller *ast.CallExpr
-	indx   int
+	caller   *ast.CallExpr
+	indx     int
+	expr     ast.Expr // the value expression, the repeated one in case of implicit repetition
+	typeExpr ast.Expr
+	iota     int
+	decl     *ast.GenDecl // the const declaration, to keep the groups
+	file     *File
+	pkg      *Package
+	val      constant.Value // only in ParsePackageWithTypes
 }
 
 func constantFromValue(name string, indx int, e []ast.Expr, src string, f *File, p *Package) *Constant {
@@ -47,12 +68,12 @@ func constantFromValue(name string, indx int, e []ast.Expr, src string, f *File,
 			case token.IMAG:
 				t = &IdentType{
 					srcBase{p, getSource(data, src)},
-					"complex64",
+					"complex128",
 				}
 			case token.CHAR:
 				t = &IdentType{
 					srcBase{p, getSource(data, src)},
-					"char",
+					"rune",
 				}
 			case token.STRING:
 				t = &IdentType{
@@ -114,8 +135,216 @@ func NewConstant(v *ast.ValueSpec, c *ast.CommentGroup, src string, f *File, p *
 		}
 		n.Name = name
 		n.Docs = docsFromNodeDoc(c, v.Doc)
+		n.GroupDocs, n.SpecDocs = docsFromNodeDoc(c), docsFromNodeDoc(v.Doc)
+		n.Comment = append(inlineComment(f, v.Names, i, valueSpecEnd(v)), docsFromNodeDoc(v.Comment)...)
+		n.Annotations = annotationsFromNodeDoc(f, c, v.Doc)
+		n.typeExpr = v.Type
+		if i < len(v.Values) {
+			n.expr = v.Values[i]
+			n.Initializer = getSource(n.expr, src)
+			n.Expression = newExpression(n.expr, src, f, p)
+		}
+		n.file = f
+		n.pkg = p
 		res = append(res, n)
 	}
 
 	return res
 }
+
+// Evaluate try to compute the value of the constant at compile time. it support
+// literals, iota, other constants (in this package or imported ones), conversions
+// and the unary and binary operators
+func (c *Constant) Evaluate() (constant.Value, error) {
+	if c.val != nil {
+		return c.val, nil
+	}
+	return c.evaluate(make(map[*Constant]bool))
+}
+
+func (c *Constant) evaluate(seen map[*Constant]bool) (constant.Value, error) {
+	if seen[c] {
+		return nil, fmt.Errorf("constant %s is defined in terms of itself", c.Name)
+	}
+	if c.expr == nil {
+		return nil, fmt.Errorf("constant %s has no value", c.Name)
+	}
+	seen[c] = true
+	defer delete(seen, c)
+
+	v, err := c.evalExpr(c.expr, seen)
+	if err != nil || c.typeExpr == nil {
+		return v, err
+	}
+	// the typed constant has the kind of its type, so the F / 2 for F float64 = 3 is 1.5
+	basic := c.conversionType(c.typeExpr)
+	if basic == "" {
+		return v, nil
+	}
+	res := convertConstant(v, basic)
+	if res.Kind() == constant.Unknown {
+		return nil, fmt.Errorf("can not convert %s to %s in constant %s", v, basic, c.Name)
+	}
+	return res, nil
+}
+
+func (c *Constant) lookup(pkg *Package, name string, seen map[*Constant]bool) (constant.Value, error) {
+	if pkg == nil {
+		return nil, fmt.Errorf("can not find constant %s, no package", name)
+	}
+	other, err := pkg.FindConstant(name)
+	if err != nil {
+		return nil, err
+	}
+	return other.evaluate(seen)
+}
+
+func (c *Constant) evalExpr(e ast.Expr, seen map[*Constant]bool) (constant.Value, error) {
+	switch t := e.(type) {
+	case *ast.BasicLit:
+		v := constant.MakeFromLiteral(t.Value, t.Kind, 0)
+		if v.Kind() == constant.Unknown {
+			return nil, fmt.Errorf("invalid literal %s", t.Value)
+		}
+		return v, nil
+	case *ast.ParenExpr:
+		return c.evalExpr(t.X, seen)
+	case *ast.Ident:
+		switch t.Name {
+		case "iota":
+			return constant.MakeInt64(int64(c.iota)), nil
+		case "true", "false":
+			return constant.MakeBool(t.Name == "true"), nil
+		}
+		return c.lookup(c.pkg, t.Name, seen)
+	case *ast.SelectorExpr:
+		id, ok := t.X.(*ast.Ident)
+		if !ok || c.file == nil {
+			return nil, fmt.Errorf("unsupported selector in constant %s", c.Name)
+		}
+		imp := getImport(nameFromIdent(id), c.file)
+		if imp == nil {
+			return nil, fmt.Errorf("import %s not found", nameFromIdent(id))
+		}
+		return c.lookup(imp.LoadPackage(), nameFromIdent(t.Sel), seen)
+	case *ast.UnaryExpr:
+		x, err := c.evalExpr(t.X, seen)
+		if err != nil {
+			return nil, err
+		}
+		if t.Op == token.NOT && x.Kind() != constant.Bool {
+			return nil, fmt.Errorf("invalid operation ! on %s", x)
+		}
+		return constant.UnaryOp(t.Op, x, 0), nil
+	case *ast.BinaryExpr:
+		return c.evalBinary(t, seen)
+	case *ast.CallExpr:
+		// the type conversion, the builtin functions like len are not supported
+		basic := c.conversionType(t.Fun)
+		if basic == "" || len(t.Args) != 1 {
+			return nil, fmt.Errorf("unsupported call in constant %s", c.Name)
+		}
+		x, err := c.evalExpr(t.Args[0], seen)
+		if err != nil {
+			return nil, err
+		}
+		res := convertConstant(x, basic)
+		if res.Kind() == constant.Unknown {
+			return nil, fmt.Errorf("can not convert %s to %s in constant %s", x, basic, c.Name)
+		}
+		return res, nil
+	}
+
+	return nil, fmt.Errorf("unsupported expression in constant %s", c.Name)
+}
+
+// conversionType return the underlying builtin type of the conversion, it is empty if
+// the call is not a conversion
+func (c *Constant) conversionType(fun ast.Expr) string {
+	if c.pkg == nil {
+		if id, ok := fun.(*ast.Ident); ok && predeclared[id.Name] {
+			return id.Name
+		}
+		return ""
+	}
+	if _, ok := fun.(*ast.SelectorExpr); ok && c.file == nil {
+		return ""
+	}
+	et := newTyper(c.pkg, c.file)
+	t := et.typeExpr(fun)
+	if t == nil {
+		return ""
+	}
+	if id, ok := et.underlying(t).(*IdentType); ok && predeclared[id.Ident] {
+		return id.Ident
+	}
+	return ""
+}
+
+// convertConstant apply the conversion to the builtin type, the result is unknown if
+// the conversion is not valid
+func convertConstant(x constant.Value, basic string) constant.Value {
+	switch basic {
+	case "int", "int8", "int16", "int32", "int64", "rune",
+		"uint", "uint8", "uint16", "uint32", "uint64", "uintptr", "byte":
+		return constant.ToInt(x)
+	case "float32", "float64":
+		return constant.ToFloat(x)
+	case "complex64", "complex128":
+		return constant.ToComplex(x)
+	case "string":
+		if x.Kind() == constant.String {
+			return x
+		}
+		// string(rune)
+		if i, ok := constant.Int64Val(x); ok && x.Kind() == constant.Int {
+			return constant.MakeString(string(rune(i)))
+		}
+	case "bool":
+		if x.Kind() == constant.Bool {
+			return x
+		}
+	}
+	return constant.MakeUnknown()
+}
+
+func (c *Constant) evalBinary(t *ast.BinaryExpr, seen map[*Constant]bool) (res constant.Value, err error) {
+	// go/constant panics on mismatched operands, like "a" + 1
+	defer func() {
+		if e := recover(); e != nil {
+			res, err = nil, fmt.Errorf("invalid operation in constant %s: %v", c.Name, e)
+		}
+	}()
+	x, err := c.evalExpr(t.X, seen)
+	if err != nil {
+		return nil, err
+	}
+	y, err := c.evalExpr(t.Y, seen)
+	if err != nil {
+		return nil, err
+	}
+
+	switch t.Op {
+	case token.SHL, token.SHR:
+		s, ok := constant.Uint64Val(constant.ToInt(y))
+		if !ok || x.Kind() != constant.Int {
+			return nil, fmt.Errorf("invalid shift in constant %s", c.Name)
+		}
+		return constant.Shift(x, t.Op, uint(s)), nil
+	case token.EQL, token.NEQ, token.LSS, token.LEQ, token.GTR, token.GEQ:
+		return constant.MakeBool(constant.Compare(x, t.Op, y)), nil
+	case token.QUO, token.REM:
+		if constant.Sign(y) == 0 {
+			return nil, fmt.Errorf("division by zero in constant %s", c.Name)
+		}
+		if t.Op == token.QUO && x.Kind() == constant.Int && y.Kind() == constant.Int {
+			// integer division
+			return constant.BinaryOp(x, token.QUO_ASSIGN, y), nil
+		}
+	}
+
+	if x.Kind() == constant.Unknown || y.Kind() == constant.Unknown {
+		return nil, fmt.Errorf("invalid operand in constant %s", c.Name)
+	}
+	return constant.BinaryOp(x, t.Op, y), nil
+}
@@ -80,7 +80,7 @@ func TestConstant(t *testing.T) {
 			So(err, ShouldBeNil)
 			So(i.Name, ShouldEqual, "i4")
 			So(i.Name, ShouldEqual, "i4")
-			So(i.Type.(*IdentType).Ident, ShouldEqual, "char")
+			So(i.Type.(*IdentType).Ident, ShouldEqual, "rune")
 		})
 
 		Convey("by value i6", func() {
@@ -88,7 +88,7 @@ func TestConstant(t *testing.T) {
 			So(err, ShouldBeNil)
 			So(i.Name, ShouldEqual, "i6")
 			So(i.Name, ShouldEqual, "i6")
-			So(i.Type.(*IdentType).Ident, ShouldEqual, "complex64")
+			So(i.Type.(*IdentType).Ident, ShouldEqual, "complex128")
 		})
 	})
 }
@@ -0,0 +1,396 @@
+package humanize
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strconv"
+)
+
+// typesBuilder convert the humanize types to the go/types types. for the packages
+// that are not loaded with ParsePackageWithTypes, the named types are created base on
+// the humanize model
+type typesBuilder struct {
+	named map[string]*types.Named
+	pkgs  map[string]*types.Package
+}
+
+func (tb *typesBuilder) pkg(p *Package) *types.Package {
+	if p == nil {
+		return nil
+	}
+	if p.types != nil {
+		return p.types
+	}
+	if tp, ok := tb.pkgs[p.Path]; ok {
+		return tp
+	}
+	tp := types.NewPackage(p.Path, p.Name)
+	tb.pkgs[p.Path] = tp
+	return tp
+}
+
+func (tb *typesBuilder) namedType(p *Package, name string) (types.Type, error) {
+	if p == nil {
+		return nil, fmt.Errorf("the package of type %s is unknown", name)
+	}
+	if p.types != nil {
+		if obj, ok := p.types.Scope().Lookup(name).(*types.TypeName); ok {
+			return obj.Type(), nil
+		}
+	}
+	key := p.Path + "." + name
+	if n, ok := tb.named[key]; ok {
+		return n, nil
+	}
+	tn, err := p.FindType(name)
+	if err != nil {
+		return nil, err
+	}
+
+	tp := tb.pkg(p)
+	n := types.NewNamed(types.NewTypeName(token.NoPos, tp, name, nil), nil, nil)
+	// add it before the underlying, the type may refer to itself
+	tb.named[key] = n
+	u, err := tb.convert(tn.Type)
+	if err != nil {
+		return nil, err
+	}
+	if u.Underlying() == nil {
+		return nil, fmt.Errorf("invalid recursive type %s", name)
+	}
+	n.SetUnderlying(u.Underlying())
+
+	for _, fn := range tn.Methods {
+		if err := tb.addMethod(n, tp, fn, false); err != nil {
+			return nil, err
+		}
+	}
+	for _, fn := range tn.StarMethods {
+		if err := tb.addMethod(n, tp, fn, true); err != nil {
+			return nil, err
+		}
+	}
+	return n, nil
+}
+
+func (tb *typesBuilder) addMethod(n *types.Named, tp *types.Package, fn *Function, pointer bool) error {
+	var rt types.Type = n
+	if pointer {
+		rt = types.NewPointer(n)
+	}
+	name := ""
+	if fn.Receiver != nil {
+		name = fn.Receiver.Name
+	}
+	sig, err := tb.signature(fn.Type, types.NewVar(token.NoPos, tp, name, rt), tp)
+	if err != nil {
+		return err
+	}
+	n.AddMethod(types.NewFunc(token.NoPos, tp, removeReceiver(fn.Name), sig))
+	return nil
+}
+
+func (tb *typesBuilder) tuple(vars []*Variable, tp *types.Package) (*types.Tuple, error) {
+	var res []*types.Var
+	for _, v := range vars {
+		t, err := tb.convert(v.Type)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, types.NewParam(token.NoPos, tp, v.Name, t))
+	}
+	return types.NewTuple(res...), nil
+}
+
+func (tb *typesBuilder) signature(ft *FuncType, recv *types.Var, tp *types.Package) (*types.Signature, error) {
+	params, err := tb.tuple(ft.Parameters, tp)
+	if err != nil {
+		return nil, err
+	}
+	results, err := tb.tuple(ft.Results, tp)
+	if err != nil {
+		return nil, err
+	}
+	return types.NewSignatureType(recv, nil, nil, params, results, ft.Variadic), nil
+}
+
+func (tb *typesBuilder) convert(t Type) (types.Type, error) {
+	switch x := t.(type) {
+	case nil:
+		return nil, fmt.Errorf("nil type")
+	case *IdentType:
+		if obj, ok := types.Universe.Lookup(x.Ident).(*types.TypeName); ok {
+			return obj.Type(), nil
+		}
+		return tb.namedType(x.Package(), x.Ident)
+	case *SelectorType:
+		return tb.namedType(x.Package(), x.Type.GetDefinition())
+	case *StarType:
+		target, err := tb.convert(x.Target)
+		if err != nil {
+			return nil, err
+		}
+		return types.NewPointer(target), nil
+	case *EllipsisType:
+		return nil, fmt.Errorf("the array length of %s is unknown", x.GetDefinition())
+	case *ArrayType:
+		elem, err := tb.convert(x.Type)
+		if err != nil {
+			return nil, err
+		}
+		if x.Slice {
+			return types.NewSlice(elem), nil
+		}
+		return types.NewArray(elem, int64(x.Len)), nil
+	case *MapType:
+		key, err := tb.convert(x.Key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := tb.convert(x.Value)
+		if err != nil {
+			return nil, err
+		}
+		return types.NewMap(key, value), nil
+	case *ChannelType:
+		elem, err := tb.convert(x.Type)
+		if err != nil {
+			return nil, err
+		}
+		dir := types.SendRecv
+		switch x.Direction {
+		case ast.SEND:
+			dir = types.SendOnly
+		case ast.RECV:
+			dir = types.RecvOnly
+		}
+		return types.NewChan(dir, elem), nil
+	case *FuncType:
+		return tb.signature(x, nil, tb.pkg(x.Package()))
+	case *StructType:
+		tp := tb.pkg(x.Package())
+		var (
+			fields []*types.Var
+			tags   []string
+		)
+		for _, e := range x.Embeds {
+			et, err := tb.convert(e.Type)
+			if err != nil {
+				return nil, err
+			}
+			name := removeReceiver(removeStar(e.Type.GetDefinition()))
+			fields = append(fields, types.NewField(token.NoPos, tp, name, et, true))
+			tags = append(tags, string(e.Tags))
+		}
+		for _, f := range x.Fields {
+			ft, err := tb.convert(f.Type)
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, types.NewField(token.NoPos, tp, f.Name, ft, false))
+			tags = append(tags, string(f.Tags))
+		}
+		return types.NewStruct(fields, tags), nil
+	case *InterfaceType:
+		tp := tb.pkg(x.Package())
+		var (
+			methods []*types.Func
+			embeds  []types.Type
+		)
+		for _, fn := range x.Functions {
+			sig, err := tb.signature(fn.Type, nil, tp)
+			if err != nil {
+				return nil, err
+			}
+			methods = append(methods, types.NewFunc(token.NoPos, tp, fn.Name, sig))
+		}
+		for _, e := range x.Embed {
+			et, err := tb.convert(e)
+			if err != nil {
+				return nil, err
+			}
+			embeds = append(embeds, et)
+		}
+		return types.NewInterfaceType(methods, embeds).Complete(), nil
+	}
+
+	return nil, fmt.Errorf("unsupported type %T", t)
+}
+
+// ToTypesType convert the humanize type to the go/types type. the named types of
+// the packages loaded with ParsePackageWithTypes are the checked ones, for the
+// other packages a named type is created from the humanize model
+func ToTypesType(t Type) (types.Type, error) {
+	tb := &typesBuilder{
+		named: make(map[string]*types.Named),
+		pkgs:  make(map[string]*types.Package),
+	}
+	return tb.convert(t)
+}
+
+// FromTypesType convert a go/types type to the humanize type. all named types are
+// selector types, except the predeclared ones
+func FromTypesType(t types.Type) Type {
+	tc := &typesConverter{}
+	return tc.convert(t)
+}
+
+// fieldList create the parameter or result list, the variables in a group are in
+// a single field. for variadic, the last one is ...T
+func fieldList(vars []*Variable, groups []int, variadic bool, imports *ImportSet) *ast.FieldList {
+	res := &ast.FieldList{}
+	for i := 0; i < len(vars); {
+		n := 1
+		if len(groups) > len(res.List) {
+			n = groups[len(res.List)]
+		}
+		if n < 1 || i+n > len(vars) {
+			n = 1
+		}
+		f := &ast.Field{Type: ToAstExpr(vars[i].Type, imports)}
+		if variadic && i+n == len(vars) {
+			if at, ok := vars[i].Type.(*ArrayType); ok {
+				f.Type = nil
+				if elt := ToAstExpr(at.Type, imports); elt != nil {
+					f.Type = &ast.Ellipsis{Elt: elt}
+				}
+			}
+		}
+		if f.Type == nil {
+			// the types that are not in the model, like the generic ones
+			return nil
+		}
+		for _, v := range vars[i : i+n] {
+			if v.Name != "" {
+				f.Names = append(f.Names, ast.NewIdent(v.Name))
+			}
+		}
+		res.List = append(res.List, f)
+		i += n
+	}
+	return res
+}
+
+func tagLit(tag string) *ast.BasicLit {
+	if tag == "" {
+		return nil
+	}
+	return &ast.BasicLit{Kind: token.STRING, Value: "`" + tag + "`"}
+}
+
+// emptyOnOneLine set a fake position for an empty field list, the printer use it
+// to print struct{} and interface{} in one line
+func emptyOnOneLine(f *ast.FieldList) *ast.FieldList {
+	if len(f.List) == 0 {
+		f.Opening, f.Closing = 1, 1
+	}
+	return f
+}
+
+// ToAstExpr convert the type to a go/ast expression. the package names of selector
+// types are from the import set, if it is nil, the original import name is used. it
+// is nil if the type, or a part of it, is nil or not supported
+func ToAstExpr(t Type, imports *ImportSet) ast.Expr {
+	switch x := t.(type) {
+	case *IdentType:
+		return ast.NewIdent(x.Ident)
+	case *SelectorType:
+		if x.pkg == nil {
+			return ast.NewIdent(x.Type.GetDefinition())
+		}
+		name := x.pkg.Name
+		if imports != nil {
+			name = imports.Qualifier(x.pkg.Path)
+		}
+		if name == "" {
+			return ast.NewIdent(x.Type.GetDefinition())
+		}
+		return &ast.SelectorExpr{X: ast.NewIdent(name), Sel: ast.NewIdent(x.Type.GetDefinition())}
+	case *StarType:
+		if target := ToAstExpr(x.Target, imports); target != nil {
+			return &ast.StarExpr{X: target}
+		}
+	case *EllipsisType:
+		if elt := ToAstExpr(x.Type, imports); elt != nil {
+			return &ast.ArrayType{Len: &ast.Ellipsis{}, Elt: elt}
+		}
+	case *ArrayType:
+		elt := ToAstExpr(x.Type, imports)
+		if elt == nil {
+			return nil
+		}
+		res := &ast.ArrayType{Elt: elt}
+		if !x.Slice {
+			res.Len = &ast.BasicLit{Kind: token.INT, Value: strconv.Itoa(x.Len)}
+		}
+		return res
+	case *MapType:
+		key, value := ToAstExpr(x.Key, imports), ToAstExpr(x.Value, imports)
+		if key != nil && value != nil {
+			return &ast.MapType{Key: key, Value: value}
+		}
+	case *ChannelType:
+		dir := x.Direction
+		if dir == 0 {
+			dir = ast.SEND | ast.RECV
+		}
+		if value := ToAstExpr(x.Type, imports); value != nil {
+			return &ast.ChanType{Dir: dir, Value: value}
+		}
+	case *FuncType:
+		res := &ast.FuncType{Params: fieldList(x.Parameters, x.ParameterGroups, x.Variadic, imports)}
+		if res.Params == nil {
+			return nil
+		}
+		if len(x.Results) > 0 {
+			if res.Results = fieldList(x.Results, x.ResultGroups, false, imports); res.Results == nil {
+				return nil
+			}
+		}
+		return res
+	case *StructType:
+		fields := &ast.FieldList{}
+		for _, e := range x.Embeds {
+			et := ToAstExpr(e.Type, imports)
+			if et == nil {
+				return nil
+			}
+			fields.List = append(fields.List, &ast.Field{Type: et, Tag: tagLit(string(e.Tags))})
+		}
+		for _, f := range x.Fields {
+			ft := ToAstExpr(f.Type, imports)
+			if ft == nil {
+				return nil
+			}
+			fields.List = append(fields.List, &ast.Field{
+				Names: []*ast.Ident{ast.NewIdent(f.Name)},
+				Type:  ft,
+				Tag:   tagLit(string(f.Tags)),
+			})
+		}
+		return &ast.StructType{Fields: emptyOnOneLine(fields)}
+	case *InterfaceType:
+		methods := &ast.FieldList{}
+		for _, e := range x.Embed {
+			et := ToAstExpr(e, imports)
+			if et == nil {
+				return nil
+			}
+			methods.List = append(methods.List, &ast.Field{Type: et})
+		}
+		for _, fn := range x.Functions {
+			ft := ToAstExpr(fn.Type, imports)
+			if ft == nil {
+				return nil
+			}
+			methods.List = append(methods.List, &ast.Field{
+				Names: []*ast.Ident{ast.NewIdent(fn.Name)},
+				Type:  ft,
+			})
+		}
+		return &ast.InterfaceType{Methods: emptyOnOneLine(methods)}
+	}
+	return nil
+}
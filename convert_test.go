@@ -0,0 +1,126 @@
+package humanize
+
+import (
+	"bytes"
+	"go/ast"
+	"go/printer"
+	"go/token"
+	"go/types"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+var conv = `
+package test
+
+import "github.com/goraz/humanize/fixture"
+
+type List struct {
+	Next  *List
+	Value int ` + "`json:\"value\"`" + `
+}
+
+func (l *List) Len() int {
+	return 0
+}
+
+type Sizer interface {
+	Len() int
+}
+
+type All struct {
+	List
+	A [4]byte
+	S []string
+	M map[string]*List
+	C <-chan error
+	F func(int, string) (bool, error)
+	I interface {
+		Sizer
+		Name() string
+	}
+	H fixture.T1
+	E struct{}
+}
+`
+
+func printAst(e ast.Expr) string {
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, token.NewFileSet(), e); err != nil {
+		return err.Error()
+	}
+	return buf.String()
+}
+
+func TestConvert(t *testing.T) {
+	Convey("Convert test", t, func() {
+		var p = &Package{Path: "example.com/test", Name: "test"}
+		f, err := ParseFile(conv, p)
+		So(err, ShouldBeNil)
+		p.Files = append(p.Files, f)
+		findMethods(p)
+
+		Convey("to go/types", func() {
+			tn, err := p.FindType("All")
+			So(err, ShouldBeNil)
+			st := tn.Type.(*StructType)
+			expected := []string{
+				"[4]byte",
+				"[]string",
+				"map[string]*example.com/test.List",
+				"<-chan error",
+				"func(int, string) (bool, error)",
+				"interface{Name() string; example.com/test.Sizer}",
+			}
+			for i := range expected {
+				tt, err := ToTypesType(st.Fields[i].Type)
+				So(err, ShouldBeNil)
+				So(tt.String(), ShouldEqual, expected[i])
+			}
+
+			tt, err := ToTypesType(&IdentType{srcBase: srcBase{pkg: p}, Ident: "All"})
+			So(err, ShouldBeNil)
+			So(tt.Underlying().(*types.Struct).NumFields(), ShouldEqual, 9)
+			So(tt.Underlying().(*types.Struct).Field(0).Embedded(), ShouldBeTrue)
+			So(tt.Underlying().(*types.Struct).Tag(0), ShouldEqual, "")
+
+			lt, err := ToTypesType(&IdentType{srcBase: srcBase{pkg: p}, Ident: "List"})
+			So(err, ShouldBeNil)
+			So(lt.(*types.Named).NumMethods(), ShouldEqual, 1)
+			So(lt.Underlying().(*types.Struct).Tag(1), ShouldEqual, `json:"value"`)
+
+			sizer, err := ToTypesType(&IdentType{srcBase: srcBase{pkg: p}, Ident: "Sizer"})
+			So(err, ShouldBeNil)
+			So(types.Implements(types.NewPointer(lt), sizer.Underlying().(*types.Interface)), ShouldBeTrue)
+			So(types.Implements(lt, sizer.Underlying().(*types.Interface)), ShouldBeFalse)
+
+			_, err = ToTypesType(&IdentType{srcBase: srcBase{pkg: p}, Ident: "Missing"})
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("from go/types", func() {
+			tt := types.NewMap(types.Typ[types.String], types.NewSlice(types.NewPointer(types.Universe.Lookup("error").Type())))
+			So(FromTypesType(tt).GetDefinition(), ShouldEqual, "map[string][]*error")
+
+			obj := types.NewTypeName(token.NoPos, types.NewPackage("net/http", "http"), "Handler", nil)
+			named := types.NewNamed(obj, types.NewInterfaceType(nil, nil), nil)
+			sel := FromTypesType(types.NewChan(types.SendOnly, named))
+			So(sel.GetDefinition(), ShouldEqual, "chan<- http.Handler")
+			So(sel.(*ChannelType).Type.(*SelectorType).pkg.Path, ShouldEqual, "net/http")
+		})
+
+		Convey("to go/ast", func() {
+			tn, err := p.FindType("All")
+			So(err, ShouldBeNil)
+			st := tn.Type.(*StructType)
+			imports := NewImportSet()
+			So(printAst(ToAstExpr(st.Fields[4].Type, imports)), ShouldEqual, "func(int, string) (bool, error)")
+			So(printAst(ToAstExpr(st.Fields[2].Type, imports)), ShouldEqual, "map[string]*List")
+			So(printAst(ToAstExpr(st.Fields[3].Type, imports)), ShouldEqual, "<-chan error")
+			So(printAst(ToAstExpr(st.Fields[6].Type, imports)), ShouldEqual, "fixture.T1")
+			So(printAst(ToAstExpr(st.Fields[7].Type, imports)), ShouldEqual, "struct{}")
+			So(printAst(ToAstExpr(tn.Type, nil)), ShouldContainSubstring, "I\tinterface {\n\t\tSizer\n\t\tName() string\n\t}")
+		})
+	})
+}
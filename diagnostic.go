@@ -0,0 +1,20 @@
+package humanize
+
+import (
+	"fmt"
+	"go/token"
+)
+
+// Diagnostic is a non fatal problem found while loading a package
+type Diagnostic struct {
+	Pos     token.Position
+	Message string
+}
+
+// String return the diagnostic in file:line:column: message format
+func (d Diagnostic) String() string {
+	if !d.Pos.IsValid() {
+		return d.Message
+	}
+	return fmt.Sprintf("%s: %s", d.Pos, d.Message)
+}
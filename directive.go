@@ -0,0 +1,135 @@
+package humanize
+
+import (
+	"go/ast"
+	"go/build/constraint"
+	"go/token"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Directive is a compiler directive comment, like //go:generate, //go:embed or //line
+type Directive struct {
+	// Name is the directive name with the namespace, like go:generate, or line
+	Name string
+	// Text is the rest of the line after the name
+	Text string
+	// Args is the arguments, for go:generate it is split like the go tool
+	Args []string
+	Pos  token.Position
+	// Constraint is the parsed expression of the go:build directive
+	Constraint constraint.Expr
+}
+
+// LinkName return the local name and the target of the go:linkname directive
+func (d *Directive) LinkName() (local, target string, ok bool) {
+	if d.Name != "go:linkname" || len(d.Args) == 0 {
+		return "", "", false
+	}
+	if len(d.Args) > 1 {
+		target = d.Args[1]
+	}
+	return d.Args[0], target, true
+}
+
+// isDirective check if the comment text (without //) is a directive, the same as the
+// go/ast rules
+func isDirective(c string) bool {
+	if strings.HasPrefix(c, "line ") || strings.HasPrefix(c, "extern ") || strings.HasPrefix(c, "export ") {
+		return true
+	}
+	colon := strings.Index(c, ":")
+	if colon <= 0 || colon+1 >= len(c) {
+		return false
+	}
+	for i := 0; i <= colon+1; i++ {
+		if i == colon {
+			continue
+		}
+		b := c[i]
+		if !('a' <= b && b <= 'z' || '0' <= b && b <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// isDirectiveComment check if the raw comment is a directive
+func isDirectiveComment(c string) bool {
+	return strings.HasPrefix(c, "//") && isDirective(c[2:])
+}
+
+// splitGenerate split the go:generate line like the go tool, the double quoted
+// strings are a single argument. the environment variables are not expanded
+func splitGenerate(line string) []string {
+	var res []string
+	for {
+		line = strings.TrimLeftFunc(line, unicode.IsSpace)
+		if line == "" {
+			break
+		}
+		if line[0] == '"' {
+			i := 1
+			for ; i < len(line); i++ {
+				if line[i] == '\\' {
+					i++
+					continue
+				}
+				if line[i] == '"' {
+					break
+				}
+			}
+			if i < len(line) {
+				if s, err := strconv.Unquote(line[:i+1]); err == nil {
+					res = append(res, s)
+					line = line[i+1:]
+					continue
+				}
+			}
+		}
+		i := strings.IndexFunc(line, unicode.IsSpace)
+		if i < 0 {
+			i = len(line)
+		}
+		res = append(res, line[:i])
+		line = line[i:]
+	}
+	return res
+}
+
+func parseDirective(text string) *Directive {
+	name, rest, _ := strings.Cut(text, " ")
+	res := &Directive{Name: name, Text: strings.TrimSpace(rest)}
+	switch name {
+	case "go:generate":
+		res.Args = splitGenerate(res.Text)
+	case "go:build":
+		if x, err := constraint.Parse("//" + text); err == nil {
+			res.Constraint = x
+		}
+		res.Args = strings.Fields(res.Text)
+	default:
+		res.Args = strings.Fields(res.Text)
+	}
+	return res
+}
+
+// directivesFromNodeDoc extract the directives from the comments
+func directivesFromNodeDoc(f *File, cgs ...*ast.CommentGroup) []*Directive {
+	var res []*Directive
+	for _, cg := range cgs {
+		if cg == nil {
+			continue
+		}
+		for _, c := range cg.List {
+			if !isDirectiveComment(c.Text) {
+				continue
+			}
+			d := parseDirective(c.Text[2:])
+			d.Pos = f.position(c.Slash)
+			res = append(res, d)
+		}
+	}
+	return res
+}
@@ -0,0 +1,101 @@
+package humanize
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+var directiveSrc = `//go:build linux && (amd64 || arm64)
+
+// Package test is for directives
+package test
+
+import _ "unsafe"
+
+//go:generate stringer -type=Kind -output "kind string.go"
+//go:generate echo "a \"quoted\" arg" $GOFILE
+
+// Kind is a kind
+//go:noinline
+type Kind int
+
+// now is the linked one
+//
+//go:linkname now runtime.nanotime
+func now() int64
+
+// files is the embeded files
+//go:embed *.txt
+var files string
+
+//line other.go:10
+func F() {
+	//go:generate inside the body
+}
+`
+
+func TestDirective(t *testing.T) {
+	Convey("Directive test", t, func() {
+		var p = &Package{}
+		f, err := parseFile("dir.go", directiveSrc, p)
+		So(err, ShouldBeNil)
+		p.Files = append(p.Files, f)
+
+		Convey("file", func() {
+			So(len(f.Directives), ShouldEqual, 8)
+			b := f.Directives[0]
+			So(b.Name, ShouldEqual, "go:build")
+			So(b.Constraint, ShouldNotBeNil)
+			So(b.Constraint.String(), ShouldEqual, "linux && (amd64 || arm64)")
+			So(b.Constraint.Eval(func(tag string) bool { return tag == "linux" || tag == "arm64" }), ShouldBeTrue)
+			So(b.Constraint.Eval(func(tag string) bool { return tag == "linux" }), ShouldBeFalse)
+			So(b.Pos.Line, ShouldEqual, 1)
+
+			g := f.Directives[1]
+			So(g.Name, ShouldEqual, "go:generate")
+			So(g.Args, ShouldResemble, []string{"stringer", "-type=Kind", "-output", "kind string.go"})
+			So(g.Pos.Filename, ShouldEqual, "dir.go")
+			So(g.Pos.Line, ShouldEqual, 8)
+			So(f.Directives[2].Args, ShouldResemble, []string{"echo", `a "quoted" arg`, "$GOFILE"})
+			So(f.Directives[7].Text, ShouldEqual, "inside the body")
+			So(f.Directives[6].Name, ShouldEqual, "line")
+			So(f.Directives[6].Args, ShouldResemble, []string{"other.go:10"})
+
+			So(len(f.LooseComments), ShouldEqual, 0)
+			So(f.Docs, ShouldResemble, Docs{"// Package test is for directives"})
+		})
+
+		Convey("declarations", func() {
+			tn, err := p.FindType("Kind")
+			So(err, ShouldBeNil)
+			So(len(tn.Directives), ShouldEqual, 1)
+			So(tn.Directives[0].Name, ShouldEqual, "go:noinline")
+			So(tn.Docs, ShouldResemble, Docs{"// Kind is a kind"})
+
+			fn, err := p.FindFunction("now")
+			So(err, ShouldBeNil)
+			So(len(fn.Directives), ShouldEqual, 1)
+			local, target, ok := fn.Directives[0].LinkName()
+			So(ok, ShouldBeTrue)
+			So(local, ShouldEqual, "now")
+			So(target, ShouldEqual, "runtime.nanotime")
+			So(fn.Docs.Text(), ShouldEqual, "now is the linked one\n")
+
+			_, _, ok = tn.Directives[0].LinkName()
+			So(ok, ShouldBeFalse)
+
+			v, err := p.FindVariable("files")
+			So(err, ShouldBeNil)
+			So(len(v.Directives), ShouldEqual, 1)
+			So(v.Directives[0].Name, ShouldEqual, "go:embed")
+			So(v.Directives[0].Args, ShouldResemble, []string{"*.txt"})
+			So(len(v.Docs), ShouldEqual, 1)
+
+			fn, err = p.FindFunction("F")
+			So(err, ShouldBeNil)
+			So(fn.Directives[0].Name, ShouldEqual, "line")
+			So(len(fn.Docs), ShouldEqual, 0)
+		})
+	})
+}
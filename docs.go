@@ -1,4 +1,116 @@
 package humanize
 
-// Docs is use to store documents
+import (
+	"go/ast"
+	"go/doc"
+	"go/doc/comment"
+	"go/token"
+	"strings"
+)
+
+// Docs is use to store documents, each item is a raw comment with the // or /* */
 type Docs []string
+
+func (d Docs) group() *ast.CommentGroup {
+	cg := &ast.CommentGroup{}
+	for i := range d {
+		cg.List = append(cg.List, &ast.Comment{Text: d[i]})
+	}
+	return cg
+}
+
+// Text return the text of the comments without the comment markers, like the
+// ast.CommentGroup.Text
+func (d Docs) Text() string {
+	return d.group().Text()
+}
+
+// description return the text of the docs without the annotation lines, for the
+// descriptions in the exported schemas
+func description(d Docs) string {
+	var res []string
+	for _, l := range strings.Split(d.Text(), "\n") {
+		if !strings.HasPrefix(l, "@") {
+			res = append(res, l)
+		}
+	}
+	return strings.TrimSpace(strings.Join(res, "\n"))
+}
+
+// Comment return the parsed doc comment, with paragraphs, headings, code blocks,
+// lists and doc links
+func (d Docs) Comment() *comment.Doc {
+	var p comment.Parser
+	return p.Parse(d.Text())
+}
+
+// Summary return the first sentence of the docs
+func (d Docs) Summary() string {
+	var p doc.Package
+	return p.Synopsis(d.Text())
+}
+
+// LooseComment is a comment in the file that is not attached to any declaration
+type LooseComment struct {
+	Docs Docs
+	Pos  token.Position
+}
+
+// inlineComment return the comments between the name and the next name, or the
+// end, like the /* comment */ in a, b /* comment */ int
+func inlineComment(f *File, names []*ast.Ident, i int, end token.Pos) Docs {
+	if f == nil {
+		return nil
+	}
+	next := end
+	if i+1 < len(names) {
+		next = names[i+1].Pos()
+	}
+	var res Docs
+	for _, cg := range f.comments {
+		if cg.Pos() >= names[i].End() && cg.End() <= next {
+			res = append(res, docsFromNodeDoc(cg)...)
+		}
+	}
+	return res
+}
+
+// valueSpecEnd return the position after the names in the value spec
+func valueSpecEnd(v *ast.ValueSpec) token.Pos {
+	if v.Type != nil {
+		return v.Type.Pos()
+	}
+	if len(v.Values) > 0 {
+		return v.Values[0].Pos()
+	}
+	return v.End()
+}
+
+// looseComments return the top level comments that are not a doc or a trailing
+// comment of any node
+func looseComments(af *ast.File, f *File) []*LooseComment {
+	used := make(map[*ast.CommentGroup]bool)
+	ast.Inspect(af, func(n ast.Node) bool {
+		if cg, ok := n.(*ast.CommentGroup); ok {
+			used[cg] = true
+		}
+		return true
+	})
+	var res []*LooseComment
+	for _, cg := range af.Comments {
+		if used[cg] {
+			continue
+		}
+		inside := false
+		for _, d := range af.Decls {
+			if cg.Pos() >= d.Pos() && cg.End() <= d.End() {
+				inside = true
+				break
+			}
+		}
+		if d := docsFromNodeDoc(cg); !inside && len(d) > 0 {
+			res = append(res, &LooseComment{Docs: d, Pos: f.position(cg.Pos())})
+		}
+	}
+	return res
+}
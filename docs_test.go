@@ -0,0 +1,158 @@
+package humanize
+
+import (
+	"go/doc/comment"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+var docsSrc = `package test
+
+// Single is a single var. It has two sentences.
+var Single int
+
+// Group is the group doc
+var (
+	// First is the first one
+	First int
+	Second int
+)
+
+/*
+Block is in block comment.
+
+# Heading
+
+	code block
+
+The list:
+  - item one
+  - item two
+
+See [strings.Builder] for more.
+*/
+type Block struct{}
+
+// Alone is a type
+type Alone int // the alone type
+
+// a free floating comment
+
+const (
+	// Cat is a cat
+	Cat = iota // the cat
+	Dog        // the dog
+)
+
+type S struct {
+	// A is a
+	A, B /* the b */ int // a and b
+	Embeded // the embeded
+}
+
+func F() {
+	// inside the function
+}
+
+/* last one */
+`
+
+func TestDocs(t *testing.T) {
+	Convey("Docs test", t, func() {
+		var p = &Package{}
+		f, err := ParseFile(docsSrc, p)
+		So(err, ShouldBeNil)
+		p.Files = append(p.Files, f)
+
+		Convey("text", func() {
+			v, err := p.FindVariable("Single")
+			So(err, ShouldBeNil)
+			So(v.Docs, ShouldResemble, Docs{"// Single is a single var. It has two sentences."})
+			So(v.Docs.Text(), ShouldEqual, "Single is a single var. It has two sentences.\n")
+			So(v.Docs.Summary(), ShouldEqual, "Single is a single var.")
+			So(len(v.GroupDocs), ShouldEqual, 0)
+			So(v.SpecDocs, ShouldResemble, v.Docs)
+
+			tn, err := p.FindType("Block")
+			So(err, ShouldBeNil)
+			So(tn.Docs.Summary(), ShouldEqual, "Block is in block comment.")
+			So(tn.Docs.Text(), ShouldStartWith, "Block is in block comment.\n\n# Heading\n")
+			So(tn.SpecDocs.Text(), ShouldEqual, tn.Docs.Text())
+
+			var empty Docs
+			So(empty.Text(), ShouldEqual, "")
+			So(empty.Summary(), ShouldEqual, "")
+		})
+
+		Convey("group", func() {
+			v, err := p.FindVariable("First")
+			So(err, ShouldBeNil)
+			So(len(v.Docs), ShouldEqual, 2)
+			So(v.GroupDocs.Text(), ShouldEqual, "Group is the group doc\n")
+			So(v.SpecDocs.Text(), ShouldEqual, "First is the first one\n")
+
+			v, err = p.FindVariable("Second")
+			So(err, ShouldBeNil)
+			So(v.GroupDocs.Text(), ShouldEqual, "Group is the group doc\n")
+			So(len(v.SpecDocs), ShouldEqual, 0)
+
+			tn, err := p.FindType("Alone")
+			So(err, ShouldBeNil)
+			So(len(tn.GroupDocs), ShouldEqual, 0)
+			So(tn.SpecDocs.Summary(), ShouldEqual, "Alone is a type")
+		})
+
+		Convey("comments", func() {
+			tn, err := p.FindType("Alone")
+			So(err, ShouldBeNil)
+			So(tn.Comment.Text(), ShouldEqual, "the alone type\n")
+
+			c, err := p.FindConstant("Cat")
+			So(err, ShouldBeNil)
+			So(c.Comment.Text(), ShouldEqual, "the cat\n")
+			c, err = p.FindConstant("Dog")
+			So(err, ShouldBeNil)
+			So(c.Comment, ShouldResemble, Docs{"// the dog"})
+
+			tn, err = p.FindType("S")
+			So(err, ShouldBeNil)
+			st := tn.Type.(*StructType)
+			So(st.Fields[0].Comment, ShouldResemble, Docs{"// a and b"})
+			So(st.Fields[1].Comment, ShouldResemble, Docs{"/* the b */", "// a and b"})
+			So(st.Embeds[0].Comment.Text(), ShouldEqual, "the embeded\n")
+
+			So(len(f.LooseComments), ShouldEqual, 2)
+			So(f.LooseComments[0].Docs.Text(), ShouldEqual, "a free floating comment\n")
+			So(f.LooseComments[0].Pos.Line, ShouldEqual, 31)
+			So(f.LooseComments[1].Docs, ShouldResemble, Docs{"/* last one */"})
+		})
+
+		Convey("structure", func() {
+			tn, err := p.FindType("Block")
+			So(err, ShouldBeNil)
+			d := tn.Docs.Comment()
+			So(len(d.Content), ShouldEqual, 6)
+			So(d.Content[0], ShouldHaveSameTypeAs, &comment.Paragraph{})
+			h, ok := d.Content[1].(*comment.Heading)
+			So(ok, ShouldBeTrue)
+			So(h.Text[0], ShouldResemble, comment.Plain("Heading"))
+			c, ok := d.Content[2].(*comment.Code)
+			So(ok, ShouldBeTrue)
+			So(c.Text, ShouldEqual, "code block\n")
+			l, ok := d.Content[4].(*comment.List)
+			So(ok, ShouldBeTrue)
+			So(len(l.Items), ShouldEqual, 2)
+			para := d.Content[5].(*comment.Paragraph)
+			var link *comment.DocLink
+			for _, t := range para.Text {
+				if dl, ok := t.(*comment.DocLink); ok {
+					link = dl
+				}
+			}
+			So(link, ShouldNotBeNil)
+			So(link.ImportPath, ShouldEqual, "strings")
+			So(link.Name, ShouldEqual, "Builder")
+		})
+	})
+}
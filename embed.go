@@ -0,0 +1,214 @@
+package humanize
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// EmbedKind is the type of an embed variable
+type EmbedKind int
+
+const (
+	// EmbedString is the string variable with a single file
+	EmbedString EmbedKind = iota + 1
+	// EmbedBytes is the []byte variable with a single file
+	EmbedBytes
+	// EmbedFS is the embed.FS variable
+	EmbedFS
+)
+
+// EmbedFiles is the go:embed data of a variable
+type EmbedFiles struct {
+	Kind     EmbedKind
+	Patterns []string
+	// Files is the matched files, relative to the package folder with slash separator.
+	// it is empty if the package is not loaded from the disk
+	Files []string
+}
+
+func embedKind(t Type) (EmbedKind, bool) {
+	switch x := t.(type) {
+	case *IdentType:
+		if x.Ident == "string" {
+			return EmbedString, true
+		}
+	case *ArrayType:
+		if id, ok := x.Type.(*IdentType); ok && x.Slice && (id.Ident == "byte" || id.Ident == "uint8") {
+			return EmbedBytes, true
+		}
+	case *SelectorType:
+		if x.pkg != nil && x.pkg.Path == "embed" && x.Type.GetDefinition() == "FS" {
+			return EmbedFS, true
+		}
+	}
+	return 0, false
+}
+
+// isBadEmbedName check if the file or folder name can not be in a module
+func isBadEmbedName(name string) bool {
+	switch name {
+	case "", ".bzr", ".hg", ".git", ".svn":
+		return true
+	}
+	return strings.ContainsAny(name, "\"'*<>?`|:\\")
+}
+
+// embedFiles resolve a single pattern like the go tool, base on the package folder
+func embedFiles(dir, pattern string) ([]string, error) {
+	glob, all := pattern, false
+	if strings.HasPrefix(pattern, "all:") {
+		glob, all = pattern[4:], true
+	}
+	if _, err := path.Match(glob, ""); err != nil || !fs.ValidPath(glob) || glob == "." {
+		return nil, fmt.Errorf("invalid pattern syntax")
+	}
+	match, err := fs.Glob(os.DirFS(dir), glob)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []string
+	for _, rel := range match {
+		file := filepath.Join(dir, filepath.FromSlash(rel))
+		info, err := os.Lstat(file)
+		if err != nil {
+			return nil, err
+		}
+		what := "file"
+		if info.IsDir() {
+			what = "directory"
+		}
+		// the folders in the path should not be a new module
+		for d := file; len(d) > len(dir); d = filepath.Dir(d) {
+			if _, err := os.Stat(filepath.Join(d, "go.mod")); err == nil {
+				return nil, fmt.Errorf("cannot embed %s %s: in different module", what, rel)
+			}
+			if isBadEmbedName(filepath.Base(d)) {
+				return nil, fmt.Errorf("cannot embed %s %s: invalid name %s", what, rel, filepath.Base(d))
+			}
+		}
+
+		switch {
+		case info.Mode().IsRegular():
+			res = append(res, rel)
+		case info.IsDir():
+			count := 0
+			err := filepath.Walk(file, func(p string, info os.FileInfo, err error) error {
+				if err != nil {
+					return err
+				}
+				name := info.Name()
+				// the hidden files are ignored, unless the all: prefix is used
+				if p != file && (isBadEmbedName(name) || ((name[0] == '.' || name[0] == '_') && !all)) {
+					if info.IsDir() {
+						return filepath.SkipDir
+					}
+					return nil
+				}
+				if info.IsDir() {
+					if _, err := os.Stat(filepath.Join(p, "go.mod")); err == nil {
+						return filepath.SkipDir
+					}
+					return nil
+				}
+				if !info.Mode().IsRegular() {
+					return nil
+				}
+				r, err := filepath.Rel(dir, p)
+				if err != nil {
+					return err
+				}
+				count++
+				res = append(res, filepath.ToSlash(r))
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+			if count == 0 {
+				return nil, fmt.Errorf("cannot embed directory %s: contains no embeddable files", rel)
+			}
+		default:
+			return nil, fmt.Errorf("cannot embed irregular file %s", rel)
+		}
+	}
+	if len(res) == 0 {
+		return nil, fmt.Errorf("no matching files found")
+	}
+	return res, nil
+}
+
+// resolveEmbeds find the embed variables in the package and resolve their patterns,
+// the problems are in the package diagnostics
+func resolveEmbeds(p *Package) {
+	for _, f := range p.Files {
+		for _, v := range f.Variables {
+			var ds []*Directive
+			for _, d := range v.Directives {
+				if d.Name == "go:embed" {
+					ds = append(ds, d)
+				}
+			}
+			if len(ds) == 0 {
+				continue
+			}
+			if v.Type == nil || v.typeExpr == nil {
+				p.Diagnostics = append(p.Diagnostics, Diagnostic{
+					Pos:     ds[0].Pos,
+					Message: fmt.Sprintf("embed variable %s must have an explicit type", v.Name),
+				})
+				continue
+			}
+			kind, ok := embedKind(v.Type)
+			if !ok {
+				p.Diagnostics = append(p.Diagnostics, Diagnostic{
+					Pos:     ds[0].Pos,
+					Message: fmt.Sprintf("go:embed cannot apply to var %s of type %s", v.Name, v.Type.GetDefinition()),
+				})
+				continue
+			}
+			ef := &EmbedFiles{Kind: kind}
+			seen := make(map[string]bool)
+			for _, d := range ds {
+				patterns, err := splitAnnotation(d.Text)
+				if err != nil || len(patterns) == 0 {
+					p.Diagnostics = append(p.Diagnostics, Diagnostic{Pos: d.Pos, Message: "invalid go:embed patterns"})
+					continue
+				}
+				for _, pt := range patterns {
+					ef.Patterns = append(ef.Patterns, pt)
+					if p.Dir == "" {
+						continue
+					}
+					files, err := embedFiles(p.Dir, pt)
+					if err != nil {
+						p.Diagnostics = append(p.Diagnostics, Diagnostic{
+							Pos:     d.Pos,
+							Message: fmt.Sprintf("pattern %s: %s", pt, err),
+						})
+						continue
+					}
+					for _, fl := range files {
+						if !seen[fl] {
+							seen[fl] = true
+							ef.Files = append(ef.Files, fl)
+						}
+					}
+				}
+			}
+			sort.Strings(ef.Files)
+			if kind != EmbedFS && len(ef.Files) > 1 {
+				p.Diagnostics = append(p.Diagnostics, Diagnostic{
+					Pos:     ds[0].Pos,
+					Message: fmt.Sprintf("invalid go:embed: multiple files for type %s", v.Type.GetDefinition()),
+				})
+			}
+			v.EmbedFiles = ef
+		}
+	}
+}
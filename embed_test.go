@@ -0,0 +1,125 @@
+package humanize
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+var embedSrc = `package test
+
+import (
+	"embed"
+)
+
+//go:embed version.txt
+var version string
+
+//go:embed logo.png
+var logo []byte
+
+//go:embed static "with space.txt"
+//go:embed all:hidden
+var assets embed.FS
+
+//go:embed missing/*.txt
+var missing embed.FS
+
+//go:embed sub
+var sub embed.FS
+
+//go:embed *.txt
+var many string
+
+//go:embed version.txt
+var wrong int
+
+//go:embed version.txt
+var implicit = load()
+
+var normal string
+
+func load() string {
+	return ""
+}
+`
+
+func writeEmbedFiles(dir string, files ...string) {
+	for _, f := range files {
+		p := filepath.Join(dir, filepath.FromSlash(f))
+		assertNil(os.MkdirAll(filepath.Dir(p), 0755))
+		assertNil(os.WriteFile(p, []byte(f), 0644))
+	}
+}
+
+func TestEmbed(t *testing.T) {
+	Convey("Embed test", t, func() {
+		dir := t.TempDir()
+		writeEmbedFiles(dir,
+			"version.txt", "logo.png", "with space.txt",
+			"static/index.html", "static/.hidden", "static/_ignored", "static/css/main.css",
+			"hidden/.env", "hidden/_x", "hidden/a.txt",
+			"sub/go.mod", "sub/file.txt",
+		)
+
+		var p = &Package{Dir: dir}
+		f, err := parseFile(filepath.Join(dir, "embed.go"), embedSrc, p)
+		So(err, ShouldBeNil)
+		p.Files = append(p.Files, f)
+		resolveEmbeds(p)
+
+		Convey("single file", func() {
+			v, err := p.FindVariable("version")
+			So(err, ShouldBeNil)
+			So(v.EmbedFiles, ShouldNotBeNil)
+			So(v.EmbedFiles.Kind, ShouldEqual, EmbedString)
+			So(v.EmbedFiles.Patterns, ShouldResemble, []string{"version.txt"})
+			So(v.EmbedFiles.Files, ShouldResemble, []string{"version.txt"})
+
+			v, err = p.FindVariable("logo")
+			So(err, ShouldBeNil)
+			So(v.EmbedFiles.Kind, ShouldEqual, EmbedBytes)
+			So(v.EmbedFiles.Files, ShouldResemble, []string{"logo.png"})
+
+			v, err = p.FindVariable("normal")
+			So(err, ShouldBeNil)
+			So(v.EmbedFiles, ShouldBeNil)
+
+			v, err = p.FindVariable("implicit")
+			So(err, ShouldBeNil)
+			So(v.EmbedFiles, ShouldBeNil)
+		})
+
+		Convey("file system", func() {
+			v, err := p.FindVariable("assets")
+			So(err, ShouldBeNil)
+			So(v.EmbedFiles.Kind, ShouldEqual, EmbedFS)
+			So(v.EmbedFiles.Patterns, ShouldResemble, []string{"static", "with space.txt", "all:hidden"})
+			So(v.EmbedFiles.Files, ShouldResemble, []string{
+				"hidden/.env",
+				"hidden/_x",
+				"hidden/a.txt",
+				"static/css/main.css",
+				"static/index.html",
+				"with space.txt",
+			})
+		})
+
+		Convey("diagnostics", func() {
+			var msgs []string
+			for _, d := range p.Diagnostics {
+				msgs = append(msgs, d.Message)
+			}
+			So(msgs, ShouldResemble, []string{
+				"pattern missing/*.txt: no matching files found",
+				"pattern sub: cannot embed directory sub: in different module",
+				"invalid go:embed: multiple files for type string",
+				"go:embed cannot apply to var wrong of type int",
+				"embed variable implicit must have an explicit type",
+			})
+			So(p.Diagnostics[0].Pos.Line, ShouldEqual, 17)
+		})
+	})
+}
@@ -0,0 +1,185 @@
+package humanize
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"sort"
+)
+
+// maxEnumGap is the maximum range that is checked for gaps, bigger ranges are
+// not an enum anyway
+const maxEnumGap = 1 << 16
+
+// Enum is the group of constants with the same named type in a package
+type Enum struct {
+	Type   *TypeName
+	Values []*EnumValue
+	// HasString is true if the type has a String() string method
+	HasString bool
+	// BitFlags is true if all non zero values are a single bit, and they are not
+	// a simple sequence like 0, 1, 2
+	BitFlags bool
+	// Gaps is the list of missing values between the min and max value of an
+	// integer enum. it is always empty for bit flags
+	Gaps []int64
+	// Duplicates is the list of values shared between more than one constant
+	Duplicates [][]*EnumValue
+}
+
+// EnumValue is a single constant in the enum, with its evaluated value. the
+// value is nil if the constant can not be evaluated
+type EnumValue struct {
+	Constant *Constant
+	Value    constant.Value
+}
+
+// enumTypeName return the named type of the constant, if there is any in this package
+func (p *Package) enumTypeName(c *Constant) *TypeName {
+	var name string
+	if id, ok := c.typeExpr.(*ast.Ident); ok {
+		name = nameFromIdent(id)
+	} else if c.typeExpr == nil {
+		// typed by conversion, like A = Color(1)
+		if call, ok := c.expr.(*ast.CallExpr); ok && len(call.Args) == 1 {
+			if id, ok := call.Fun.(*ast.Ident); ok {
+				name = nameFromIdent(id)
+			}
+		}
+	}
+	if name == "" {
+		return nil
+	}
+
+	tn, err := p.FindType(name)
+	if err != nil {
+		return nil
+	}
+	return tn
+}
+
+func hasStringMethod(tn *TypeName) bool {
+	for _, fn := range append(tn.Methods, tn.StarMethods...) {
+		if removeReceiver(fn.Name) != "String" || len(fn.Type.Parameters) != 0 || len(fn.Type.Results) != 1 {
+			continue
+		}
+		if fn.Type.Results[0].Type != nil && fn.Type.Results[0].Type.GetDefinition() == "string" {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *Enum) analyze() {
+	var (
+		ints  []int64
+		seen  = make(map[int64]bool)
+		index = make(map[string]int)
+	)
+	for _, v := range e.Values {
+		if v.Value == nil {
+			continue
+		}
+		key := v.Value.ExactString()
+		if i, ok := index[key]; ok {
+			e.Duplicates[i] = append(e.Duplicates[i], v)
+		} else {
+			index[key] = len(e.Duplicates)
+			e.Duplicates = append(e.Duplicates, []*EnumValue{v})
+		}
+
+		if v.Value.Kind() != constant.Int {
+			continue
+		}
+		if i, ok := constant.Int64Val(v.Value); ok && !seen[i] {
+			seen[i] = true
+			ints = append(ints, i)
+		}
+	}
+
+	var dup [][]*EnumValue
+	for i := range e.Duplicates {
+		if len(e.Duplicates[i]) > 1 {
+			dup = append(dup, e.Duplicates[i])
+		}
+	}
+	e.Duplicates = dup
+
+	if len(ints) == 0 {
+		return
+	}
+	sort.Sort(int64Slice(ints))
+	min, max := ints[0], ints[len(ints)-1]
+
+	flags := 0
+	for _, i := range ints {
+		if i < 0 || (i != 0 && i&(i-1) != 0) {
+			flags = -1
+			break
+		}
+		if i != 0 {
+			flags++
+		}
+	}
+	// the values are distinct, so a sequence has no missing value
+	e.BitFlags = flags > 1 && max-min+1 != int64(len(ints))
+	if e.BitFlags || uint64(max-min) > maxEnumGap {
+		return
+	}
+	for i := min; i <= max; i++ {
+		if !seen[i] {
+			e.Gaps = append(e.Gaps, i)
+		}
+	}
+}
+
+type int64Slice []int64
+
+func (s int64Slice) Len() int           { return len(s) }
+func (s int64Slice) Less(i, j int) bool { return s[i] < s[j] }
+func (s int64Slice) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+// Enums return all constant groups with the same named type in the package,
+// in declaration order
+func (p *Package) Enums() []*Enum {
+	findMethods(p)
+	var (
+		res   []*Enum
+		types = make(map[*TypeName]*Enum)
+	)
+	for _, f := range p.Files {
+		for _, c := range f.Constants {
+			tn := p.enumTypeName(c)
+			if tn == nil {
+				continue
+			}
+			e, ok := types[tn]
+			if !ok {
+				e = &Enum{Type: tn, HasString: hasStringMethod(tn)}
+				types[tn] = e
+				res = append(res, e)
+			}
+			v, err := c.Evaluate()
+			if err != nil {
+				v = nil
+			}
+			e.Values = append(e.Values, &EnumValue{Constant: c, Value: v})
+		}
+	}
+
+	for i := range res {
+		res[i].analyze()
+	}
+	return res
+}
+
+// FindEnum return the enum with the type name
+func (p *Package) FindEnum(t string) (*Enum, error) {
+	for _, e := range p.Enums() {
+		if e.Type.Name == t {
+			return e, nil
+		}
+	}
+
+	return nil, fmt.Errorf("enum with name %s not found", t)
+}
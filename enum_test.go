@@ -0,0 +1,189 @@
+package humanize
+
+import (
+	"go/constant"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+var enm = `
+package test
+
+type Color int
+
+const (
+	Red Color = iota
+	Green
+	Blue
+	_
+	Black
+)
+
+const Other = Blue + 10
+
+func (c Color) String() string {
+	return ""
+}
+
+type Flag uint8
+
+const (
+	FlagNone Flag = 0
+	FlagA    Flag = 1 << iota
+	FlagB
+	FlagC
+)
+
+type Wide int
+
+const (
+	WideA Wide = 1 << 0
+	WideB Wide = 1 << 10
+	WideC Wide = 1 << 20
+)
+
+type Name string
+
+const (
+	Alice = Name("alice")
+	Bob   = Name("bob")
+	Eve   = Name("alice")
+)
+`
+
+var enm2 = `
+package test
+
+const (
+	Zero Color = iota * 2
+	Two
+)
+
+const Broken Color = Missing
+
+const (
+	N     = len("abc")
+	H     = float64(1) / 2
+	Runes = string(rune(65)) + "b"
+	Frac  = int(1.5)
+)
+
+const F float64 = 3
+
+const G = F / 2
+
+type R float64
+
+const One R = 1
+
+const Half = One / 2
+`
+
+func TestEnum(t *testing.T) {
+	Convey("Enum test", t, func() {
+		var p = &Package{}
+		f, err := ParseFile(enm, p)
+		So(err, ShouldBeNil)
+		p.Files = append(p.Files, f)
+		f2, err := ParseFile(enm2, p)
+		So(err, ShouldBeNil)
+		p.Files = append(p.Files, f2)
+
+		enums := p.Enums()
+		So(len(enums), ShouldEqual, 5)
+		Convey("integer enum across files", func() {
+			e, err := p.FindEnum("Color")
+			So(err, ShouldBeNil)
+			So(e.HasString, ShouldBeTrue)
+			So(e.BitFlags, ShouldBeFalse)
+			So(len(e.Values), ShouldEqual, 8)
+			So(e.Values[0].Constant.Name, ShouldEqual, "Red")
+			So(e.Values[3].Constant.Name, ShouldEqual, "_")
+			So(e.Values[4].Constant.Name, ShouldEqual, "Black")
+			So(e.Values[4].Value.String(), ShouldEqual, "4")
+			So(e.Values[7].Constant.Name, ShouldEqual, "Broken")
+			So(e.Values[7].Value, ShouldBeNil)
+			So(e.Gaps, ShouldBeEmpty)
+			So(len(e.Duplicates), ShouldEqual, 2)
+			So(e.Duplicates[0][0].Constant.Name, ShouldEqual, "Red")
+			So(e.Duplicates[0][1].Constant.Name, ShouldEqual, "Zero")
+			So(e.Duplicates[1][0].Constant.Name, ShouldEqual, "Blue")
+			So(e.Duplicates[1][1].Constant.Name, ShouldEqual, "Two")
+		})
+
+		Convey("bit flags", func() {
+			e, err := p.FindEnum("Flag")
+			So(err, ShouldBeNil)
+			So(e.HasString, ShouldBeFalse)
+			So(e.BitFlags, ShouldBeTrue)
+			So(e.Gaps, ShouldBeEmpty)
+			So(e.Values[3].Value.String(), ShouldEqual, "8")
+
+			// the range is too big for the gap scan
+			e, err = p.FindEnum("Wide")
+			So(err, ShouldBeNil)
+			So(e.BitFlags, ShouldBeTrue)
+			So(e.Gaps, ShouldBeEmpty)
+		})
+
+		Convey("string enum", func() {
+			e, err := p.FindEnum("Name")
+			So(err, ShouldBeNil)
+			So(len(e.Values), ShouldEqual, 3)
+			So(constant.StringVal(e.Values[1].Value), ShouldEqual, "bob")
+			So(len(e.Duplicates), ShouldEqual, 1)
+			So(e.Duplicates[0][1].Constant.Name, ShouldEqual, "Eve")
+		})
+
+		Convey("untyped constants are not enum", func() {
+			_, err := p.FindEnum("Other")
+			So(err, ShouldNotBeNil)
+			c, err := p.FindConstant("Other")
+			So(err, ShouldBeNil)
+			v, err := c.Evaluate()
+			So(err, ShouldBeNil)
+			So(v.String(), ShouldEqual, "12")
+		})
+
+		Convey("conversions", func() {
+			c, err := p.FindConstant("H")
+			So(err, ShouldBeNil)
+			v, err := c.Evaluate()
+			So(err, ShouldBeNil)
+			So(v.String(), ShouldEqual, "0.5")
+
+			c, err = p.FindConstant("Runes")
+			So(err, ShouldBeNil)
+			v, err = c.Evaluate()
+			So(err, ShouldBeNil)
+			So(constant.StringVal(v), ShouldEqual, "Ab")
+
+			c, err = p.FindConstant("N")
+			So(err, ShouldBeNil)
+			_, err = c.Evaluate()
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldEqual, "unsupported call in constant N")
+
+			c, err = p.FindConstant("Frac")
+			So(err, ShouldBeNil)
+			_, err = c.Evaluate()
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("typed constants", func() {
+			c, err := p.FindConstant("G")
+			So(err, ShouldBeNil)
+			v, err := c.Evaluate()
+			So(err, ShouldBeNil)
+			So(v.String(), ShouldEqual, "1.5")
+
+			// through the named type
+			c, err = p.FindConstant("Half")
+			So(err, ShouldBeNil)
+			v, err = c.Evaluate()
+			So(err, ShouldBeNil)
+			So(v.String(), ShouldEqual, "0.5")
+		})
+	})
+}
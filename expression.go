@@ -0,0 +1,370 @@
+package humanize
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"reflect"
+)
+
+// ExpressionKind is the kind of an expression
+type ExpressionKind int
+
+const (
+	// UnknownExpression is an expression that is not supported
+	UnknownExpression ExpressionKind = iota
+	// LiteralExpression is a basic literal like 10, "str" or 'c'
+	LiteralExpression
+	// IdentExpression is a single identifier
+	IdentExpression
+	// SelectorExpression is the X.Name expression
+	SelectorExpression
+	// CompositeExpression is a composite literal like T{...}
+	CompositeExpression
+	// KeyValueExpression is the Key: Value inside composite literals
+	KeyValueExpression
+	// CallExpression is a function call or a type conversion
+	CallExpression
+	// UnaryExpression is the operator with one operand, like -X or &X
+	UnaryExpression
+	// BinaryExpression is the operator with two operand
+	BinaryExpression
+	// FuncExpression is a function literal
+	FuncExpression
+	// IndexExpression is the X[Y] expression
+	IndexExpression
+)
+
+// Expression is a simple representation of a go expression
+type Expression struct {
+	Kind   ExpressionKind
+	Source string
+	// Value is the literal value, the identifier, the selected name or the operator
+	Value string
+	// Type is the type of the composite or function literal, it is nil for
+	// composite literals with elided type
+	Type Type
+	// X is the operand of unary, the left side of binary, the function of call, the key
+	// of key value and the base of selector and index expressions
+	X *Expression
+	// Y is the right side of binary, the value of key value and the index of index expressions
+	Y *Expression
+	// Elements is the elements of composite literal, or arguments of call
+	Elements []*Expression
+	// Function is the function literal, the name is base on the variable that it is
+	// assigned to, like handlers.func1
+	Function *Function
+}
+
+func newExpression(e ast.Expr, src string, f *File, p *Package) *Expression {
+	if e == nil {
+		return nil
+	}
+	if pe, ok := e.(*ast.ParenExpr); ok {
+		return newExpression(pe.X, src, f, p)
+	}
+
+	res := &Expression{Source: getSource(e, src)}
+	switch t := e.(type) {
+	case *ast.BasicLit:
+		res.Kind = LiteralExpression
+		res.Value = t.Value
+	case *ast.Ident:
+		res.Kind = IdentExpression
+		res.Value = nameFromIdent(t)
+	case *ast.SelectorExpr:
+		res.Kind = SelectorExpression
+		res.X = newExpression(t.X, src, f, p)
+		res.Value = nameFromIdent(t.Sel)
+	case *ast.CompositeLit:
+		res.Kind = CompositeExpression
+		if t.Type != nil {
+			res.Type = getType(t.Type, src, f, p)
+		}
+		for i := range t.Elts {
+			res.Elements = append(res.Elements, newExpression(t.Elts[i], src, f, p))
+		}
+	case *ast.KeyValueExpr:
+		res.Kind = KeyValueExpression
+		res.X = newExpression(t.Key, src, f, p)
+		res.Y = newExpression(t.Value, src, f, p)
+	case *ast.CallExpr:
+		res.Kind = CallExpression
+		res.X = newExpression(t.Fun, src, f, p)
+		for i := range t.Args {
+			res.Elements = append(res.Elements, newExpression(t.Args[i], src, f, p))
+		}
+	case *ast.UnaryExpr:
+		res.Kind = UnaryExpression
+		res.Value = t.Op.String()
+		res.X = newExpression(t.X, src, f, p)
+	case *ast.StarExpr:
+		res.Kind = UnaryExpression
+		res.Value = "*"
+		res.X = newExpression(t.X, src, f, p)
+	case *ast.BinaryExpr:
+		res.Kind = BinaryExpression
+		res.Value = t.Op.String()
+		res.X = newExpression(t.X, src, f, p)
+		res.Y = newExpression(t.Y, src, f, p)
+	case *ast.FuncLit:
+		res.Kind = FuncExpression
+		res.Type = getType(t.Type, src, f, p)
+		res.Function = &Function{Name: "func", Type: res.Type.(*FuncType), lit: t, file: f, pkg: p}
+		res.Function.Types = localTypes(res.Function, t.Body, src, f, p)
+	case *ast.IndexExpr:
+		res.Kind = IndexExpression
+		res.X = newExpression(t.X, src, f, p)
+		res.Y = newExpression(t.Index, src, f, p)
+	}
+
+	return res
+}
+
+// literals return the function literals in the expression, in the source order
+func (e *Expression) literals() []*Function {
+	if e == nil {
+		return nil
+	}
+	var res []*Function
+	if e.Function != nil {
+		res = append(res, e.Function)
+	}
+	res = append(res, e.X.literals()...)
+	res = append(res, e.Y.literals()...)
+	for i := range e.Elements {
+		res = append(res, e.Elements[i].literals()...)
+	}
+	return res
+}
+
+// nameLiterals set the name of the function literals base on the declaration name
+func (e *Expression) nameLiterals(name string) {
+	for i, fn := range e.literals() {
+		fn.Name = fmt.Sprintf("%s.func%d", name, i+1)
+	}
+}
+
+// valueEvaluator compute the value of a package level expression
+type valueEvaluator struct {
+	et   *exprTyper
+	seen map[*Variable]bool
+}
+
+// Evaluate try to compute the value of the variable initializer without running
+// the code. literals and constants are converted to bool, string, int64, uint64,
+// float64 or complex128, composite literals of structs to map[string]interface{},
+// composite literals of maps to map[interface{}]interface{} and slices and arrays
+// to []interface{}
+func (v *Variable) Evaluate() (interface{}, error) {
+	if v.expr == nil || v.file == nil {
+		return nil, fmt.Errorf("variable %s has no value", v.Name)
+	}
+	if v.indx != 0 {
+		return nil, fmt.Errorf("variable %s is a part of multi value expression", v.Name)
+	}
+	ve := &valueEvaluator{
+		et:   newTyper(v.file.pkg, v.file),
+		seen: map[*Variable]bool{v: true},
+	}
+	return ve.eval(v.expr, nil)
+}
+
+func constantToGo(v constant.Value) interface{} {
+	switch v.Kind() {
+	case constant.Bool:
+		return constant.BoolVal(v)
+	case constant.String:
+		return constant.StringVal(v)
+	case constant.Int:
+		if i, ok := constant.Int64Val(v); ok {
+			return i
+		}
+		if u, ok := constant.Uint64Val(v); ok {
+			return u
+		}
+		return v.ExactString()
+	case constant.Float:
+		f, _ := constant.Float64Val(v)
+		return f
+	case constant.Complex:
+		r, _ := constant.Float64Val(constant.Real(v))
+		i, _ := constant.Float64Val(constant.Imag(v))
+		return complex(r, i)
+	}
+	return nil
+}
+
+// constantExpr check if the expression is only literals, constants, operators and conversions
+func (ve *valueEvaluator) constantExpr(e ast.Expr) bool {
+	res := true
+	ast.Inspect(e, func(n ast.Node) bool {
+		switch t := n.(type) {
+		case *ast.CallExpr:
+			if ve.et.typeExpr(t.Fun) == nil {
+				res = false
+			}
+			return false
+		case *ast.Ident:
+			if _, err := ve.et.pkg.FindVariable(t.Name); err == nil {
+				res = false
+			}
+		case *ast.CompositeLit, *ast.FuncLit, *ast.IndexExpr, *ast.SliceExpr, *ast.StarExpr:
+			res = false
+		}
+		return res
+	})
+	return res
+}
+
+// eval compute the value, the typ is the expected type, used for elided types in
+// composite literals
+func (ve *valueEvaluator) eval(e ast.Expr, typ Type) (interface{}, error) {
+	switch t := e.(type) {
+	case *ast.ParenExpr:
+		return ve.eval(t.X, typ)
+	case *ast.CompositeLit:
+		if t.Type != nil {
+			typ = getType(t.Type, ve.et.file.src, ve.et.file, ve.et.pkg)
+		}
+		if typ == nil {
+			return nil, fmt.Errorf("composite literal without type")
+		}
+		return ve.composite(t, typ)
+	case *ast.UnaryExpr:
+		if t.Op == token.AND {
+			if st, ok := typ.(*StarType); ok {
+				typ = st.Target
+			}
+			if _, ok := t.X.(*ast.CompositeLit); ok {
+				return ve.eval(t.X, typ)
+			}
+		}
+	case *ast.Ident:
+		if t.Name == "nil" {
+			return nil, nil
+		}
+		if v, err := ve.et.pkg.FindVariable(t.Name); err == nil {
+			if ve.seen[v] || v.expr == nil || v.indx != 0 {
+				return nil, fmt.Errorf("can not evaluate the variable %s", v.Name)
+			}
+			ve.seen[v] = true
+			defer delete(ve.seen, v)
+			return ve.forFile(v.file).eval(v.expr, v.Type)
+		}
+	case *ast.CallExpr:
+		if ve.et.typeExpr(t.Fun) != nil && len(t.Args) == 1 {
+			return ve.eval(t.Args[0], typ)
+		}
+	}
+
+	if !ve.constantExpr(e) {
+		return nil, fmt.Errorf("can not evaluate %s", getSource(e, ve.et.file.src))
+	}
+	c := &Constant{Name: getSource(e, ve.et.file.src), expr: e, file: ve.et.file, pkg: ve.et.pkg}
+	v, err := c.Evaluate()
+	if err != nil {
+		return nil, err
+	}
+	return constantToGo(v), nil
+}
+
+func (ve *valueEvaluator) forFile(f *File) *valueEvaluator {
+	return &valueEvaluator{et: ve.et.forFile(f), seen: ve.seen}
+}
+
+func (ve *valueEvaluator) composite(c *ast.CompositeLit, typ Type) (interface{}, error) {
+	switch u := ve.et.underlying(typ).(type) {
+	case *StructType:
+		res := make(map[string]interface{})
+		for i := range c.Elts {
+			var (
+				name  string
+				value = c.Elts[i]
+			)
+			if kv, ok := c.Elts[i].(*ast.KeyValueExpr); ok {
+				id, ok := kv.Key.(*ast.Ident)
+				if !ok {
+					return nil, fmt.Errorf("invalid field name in %s", getSource(c, ve.et.file.src))
+				}
+				name, value = nameFromIdent(id), kv.Value
+			} else if i < len(u.Fields) && len(u.Embeds) == 0 {
+				name = u.Fields[i].Name
+			} else {
+				return nil, fmt.Errorf("can not find the field %d in %s", i, typ.GetDefinition())
+			}
+			v, err := ve.eval(value, ve.fieldType(u, name))
+			if err != nil {
+				return nil, err
+			}
+			res[name] = v
+		}
+		return res, nil
+	case *MapType:
+		res := make(map[interface{}]interface{})
+		for i := range c.Elts {
+			kv, ok := c.Elts[i].(*ast.KeyValueExpr)
+			if !ok {
+				return nil, fmt.Errorf("missing key in map literal")
+			}
+			k, err := ve.eval(kv.Key, ve.et.localize(u.Key))
+			if err != nil {
+				return nil, err
+			}
+			// the structs and the arrays are maps and slices here, they are not valid keys
+			if k != nil && !reflect.TypeOf(k).Comparable() {
+				return nil, fmt.Errorf("can not use %s as a map key", getSource(kv.Key, ve.et.file.src))
+			}
+			v, err := ve.eval(kv.Value, ve.et.localize(u.Value))
+			if err != nil {
+				return nil, err
+			}
+			res[k] = v
+		}
+		return res, nil
+	case *ArrayType:
+		return ve.array(c, ve.et.localize(u.Type), u.Len)
+	case *EllipsisType:
+		return ve.array(c, ve.et.localize(u.Type), 0)
+	}
+	return nil, fmt.Errorf("invalid composite literal type %s", typ.GetDefinition())
+}
+
+func (ve *valueEvaluator) fieldType(st *StructType, name string) Type {
+	for _, f := range st.Fields {
+		if f.Name == name {
+			return ve.et.localize(f.Type)
+		}
+	}
+	return nil
+}
+
+func (ve *valueEvaluator) array(c *ast.CompositeLit, elem Type, l int) (interface{}, error) {
+	res := make([]interface{}, l)
+	indx := 0
+	for i := range c.Elts {
+		value := c.Elts[i]
+		if kv, ok := value.(*ast.KeyValueExpr); ok {
+			k, err := ve.eval(kv.Key, nil)
+			if err != nil {
+				return nil, err
+			}
+			n, ok := k.(int64)
+			if !ok || n < 0 {
+				return nil, fmt.Errorf("invalid index %v", k)
+			}
+			indx, value = int(n), kv.Value
+		}
+		v, err := ve.eval(value, elem)
+		if err != nil {
+			return nil, err
+		}
+		for len(res) <= indx {
+			res = append(res, nil)
+		}
+		res[indx] = v
+		indx++
+	}
+	return res, nil
+}
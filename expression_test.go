@@ -0,0 +1,165 @@
+package humanize
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+var expr = `
+package test
+
+type Config struct {
+	Host  string
+	Port  int
+	Tags  []string
+	Inner *Inner
+}
+
+type Inner struct {
+	A, B int
+}
+
+type Level int
+
+const (
+	Low Level = iota
+	High
+)
+
+const base = 8000
+
+var defaults = Config{Port: base + 80, Host: "localhost", Tags: []string{"a", "b"}, Inner: &Inner{1, 2}}
+
+var levels = map[string]Level{"low": Low, "high": High}
+
+var arr = [...]int{2: 10, 20}
+
+var alias = defaults
+
+var port int = 10 * 2
+
+var called = f(1)
+
+var a, b = f(1), Level(High)
+
+const c = -base
+
+type Point struct {
+	X, Y int
+}
+
+var points = map[Point]string{{1, 2}: "a"}
+
+var grid = map[[2]int]string{{1, 2}: "a"}
+
+func f(int) int {
+	return 0
+}
+`
+
+func TestExpression(t *testing.T) {
+	Convey("Expression test", t, func() {
+		var p = &Package{}
+		f, err := ParseFile(expr, p)
+		So(err, ShouldBeNil)
+		p.Files = append(p.Files, f)
+
+		Convey("initializer", func() {
+			v, err := p.FindVariable("defaults")
+			So(err, ShouldBeNil)
+			So(v.Initializer, ShouldStartWith, "Config{Port: base + 80")
+			e := v.Expression
+			So(e.Kind, ShouldEqual, CompositeExpression)
+			So(e.Type.GetDefinition(), ShouldEqual, "Config")
+			So(len(e.Elements), ShouldEqual, 4)
+			So(e.Elements[0].Kind, ShouldEqual, KeyValueExpression)
+			So(e.Elements[0].X.Value, ShouldEqual, "Port")
+			So(e.Elements[0].Y.Kind, ShouldEqual, BinaryExpression)
+			So(e.Elements[0].Y.Value, ShouldEqual, "+")
+			So(e.Elements[0].Y.X.Value, ShouldEqual, "base")
+			So(e.Elements[0].Y.Y.Value, ShouldEqual, "80")
+			So(e.Elements[3].Y.Kind, ShouldEqual, UnaryExpression)
+			So(e.Elements[3].Y.X.Type, ShouldNotBeNil)
+
+			v, err = p.FindVariable("a")
+			So(err, ShouldBeNil)
+			So(v.Initializer, ShouldEqual, "f(1)")
+			So(v.Expression.Kind, ShouldEqual, CallExpression)
+			So(v.Expression.X.Value, ShouldEqual, "f")
+
+			v, err = p.FindVariable("b")
+			So(err, ShouldBeNil)
+			So(v.Initializer, ShouldEqual, "Level(High)")
+
+			c, err := p.FindConstant("High")
+			So(err, ShouldBeNil)
+			So(c.Initializer, ShouldBeEmpty)
+			c, err = p.FindConstant("c")
+			So(err, ShouldBeNil)
+			So(c.Initializer, ShouldEqual, "-base")
+			So(c.Expression.Kind, ShouldEqual, UnaryExpression)
+		})
+
+		Convey("evaluate struct", func() {
+			v, err := p.FindVariable("defaults")
+			So(err, ShouldBeNil)
+			val, err := v.Evaluate()
+			So(err, ShouldBeNil)
+			So(val, ShouldResemble, map[string]interface{}{
+				"Host":  "localhost",
+				"Port":  int64(8080),
+				"Tags":  []interface{}{"a", "b"},
+				"Inner": map[string]interface{}{"A": int64(1), "B": int64(2)},
+			})
+
+			v, err = p.FindVariable("alias")
+			So(err, ShouldBeNil)
+			val2, err := v.Evaluate()
+			So(err, ShouldBeNil)
+			So(val2, ShouldResemble, val)
+		})
+
+		Convey("evaluate map and array", func() {
+			v, err := p.FindVariable("levels")
+			So(err, ShouldBeNil)
+			val, err := v.Evaluate()
+			So(err, ShouldBeNil)
+			So(val, ShouldResemble, map[interface{}]interface{}{"low": int64(0), "high": int64(1)})
+
+			v, err = p.FindVariable("arr")
+			So(err, ShouldBeNil)
+			val, err = v.Evaluate()
+			So(err, ShouldBeNil)
+			So(val, ShouldResemble, []interface{}{nil, nil, int64(10), int64(20)})
+
+			// the struct and array keys can not be go map keys
+			for _, name := range []string{"points", "grid"} {
+				v, err = p.FindVariable(name)
+				So(err, ShouldBeNil)
+				_, err = v.Evaluate()
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldEqual, "can not use {1, 2} as a map key")
+			}
+		})
+
+		Convey("evaluate simple values", func() {
+			v, err := p.FindVariable("port")
+			So(err, ShouldBeNil)
+			val, err := v.Evaluate()
+			So(err, ShouldBeNil)
+			So(val, ShouldEqual, int64(20))
+
+			v, err = p.FindVariable("b")
+			So(err, ShouldBeNil)
+			val, err = v.Evaluate()
+			So(err, ShouldBeNil)
+			So(val, ShouldEqual, int64(1))
+
+			v, err = p.FindVariable("called")
+			So(err, ShouldBeNil)
+			_, err = v.Evaluate()
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
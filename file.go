@@ -11,11 +11,34 @@ type File struct {
 	FileName    string
 	PackageName string
 	Docs        Docs
+	Annotations Annotations
 	Functions   []*Function
 	Imports     []*Import
 	Variables   []*Variable
 	Constants   []*Constant
 	Types       []*TypeName
+	// LooseComments is the comments between the declarations, that are not a doc
+	// or trailing comment of anything
+	LooseComments []*LooseComment
+	// Directives is all the directives in the file, like the go tool, even the ones
+	// in the function bodies and the declaration docs
+	Directives []*Directive
+
+	fset     *token.FileSet
+	src      string
+	pkg      *Package
+	comments []*ast.CommentGroup
+	node     *ast.File
+}
+
+// position return the position of a node in this file
+func (f *File) position(pos token.Pos) token.Position {
+	if f == nil || f.fset == nil || !pos.IsValid() {
+		return token.Position{}
+	}
+	res := f.fset.Position(pos)
+	res.Filename = f.FileName
+	return res
 }
 
 type walker struct {
@@ -36,33 +59,80 @@ func docsFromNodeDoc(cgs ...*ast.CommentGroup) Docs {
 	for _, cg := range cgs {
 		if cg != nil {
 			for i := range cg.List {
-				res = append(res, cg.List[i].Text)
+				// the directives are not a part of the docs
+				if !isDirectiveComment(cg.List[i].Text) {
+					res = append(res, cg.List[i].Text)
+				}
 			}
 		}
 	}
 	return res
 }
 
+// specDoc set the doc of the spec if it has no doc
+func specDoc(s ast.Spec, doc *ast.CommentGroup) {
+	switch t := s.(type) {
+	case *ast.ImportSpec:
+		if t.Doc == nil {
+			t.Doc = doc
+		}
+	case *ast.ValueSpec:
+		if t.Doc == nil {
+			t.Doc = doc
+		}
+	case *ast.TypeSpec:
+		if t.Doc == nil {
+			t.Doc = doc
+		}
+	}
+}
+
 func (fv *walker) Visit(node ast.Node) ast.Visitor {
 	if node != nil {
 		switch t := node.(type) {
 		case *ast.File:
 			fv.File.PackageName = nameFromIdent(t.Name)
 			fv.File.Docs = docsFromNodeDoc(t.Doc)
+			fv.File.Annotations = annotationsFromNodeDoc(fv.File, t.Doc)
 		case *ast.FuncDecl:
 			fv.File.Functions = append(fv.File.Functions, NewFunction(t, fv.src, fv.File, fv.Package))
 			return nil // Do not go deeper
 		case *ast.GenDecl:
+			// without the parentheses, the doc is for the single spec not the group
+			if !t.Lparen.IsValid() && len(t.Specs) == 1 {
+				specDoc(t.Specs[0], t.Doc)
+				t.Doc = nil
+			}
 			// Constants :/
+			var last *ast.ValueSpec
 			for i := range t.Specs {
 				switch decl := t.Specs[i].(type) {
 				case *ast.ImportSpec:
 					fv.File.Imports = append(fv.File.Imports, NewImport(decl, t.Doc))
 				case *ast.ValueSpec:
 					if t.Tok.String() == "var" {
-						fv.File.Variables = append(fv.File.Variables, NewVariable(decl, t.Doc, fv.src, fv.File, fv.Package)...)
+						vs := NewVariable(decl, t.Doc, fv.src, fv.File, fv.Package)
+						for j := range vs {
+							vs[j].decl = t
+						}
+						fv.File.Variables = append(fv.File.Variables, vs...)
 					} else if t.Tok.String() == "const" {
-						fv.File.Constants = append(fv.File.Constants, NewConstant(decl, t.Doc, fv.src, fv.File, fv.Package)...)
+						// an empty value list means repeat the last one, with the new iota
+						if len(decl.Values) != 0 || decl.Type != nil {
+							last = decl
+						}
+						cs := NewConstant(decl, t.Doc, fv.src, fv.File, fv.Package)
+						for j := range cs {
+							cs[j].iota = i
+							cs[j].decl = t
+							if last != nil {
+								cs[j].typeExpr = last.Type
+								if j < len(last.Values) {
+									cs[j].expr = last.Values[j]
+								}
+							}
+						}
+						fv.File.Constants = append(fv.File.Constants, cs...)
 					}
 				case *ast.TypeSpec:
 					fv.File.Types = append(fv.File.Types, NewType(decl, t.Doc, fv.src, fv.File, fv.Package))
@@ -78,20 +148,26 @@ func (fv *walker) Visit(node ast.Node) ast.Visitor {
 
 // ParseFile try to parse a single file for its annotations
 func ParseFile(src string, p *Package) (*File, error) {
+	return parseFile("", src, p)
+}
+
+func parseFile(name, src string, p *Package) (*File, error) {
 	lastConst = nil
 	fset := token.NewFileSet()
 
-	f, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	f, err := parser.ParseFile(fset, name, src, parser.ParseComments)
 	if err != nil {
 		return &File{}, err
 	}
 
 	fv := &walker{}
 	fv.src = src
-	fv.File = &File{}
+	fv.File = &File{FileName: name, fset: fset, src: src, pkg: p, comments: f.Comments, node: f}
 	fv.Package = p
 
 	ast.Walk(fv, f)
+	fv.File.LooseComments = looseComments(f, fv.File)
+	fv.File.Directives = directivesFromNodeDoc(fv.File, f.Comments...)
 
 	return fv.File, nil
 }
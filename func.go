@@ -2,6 +2,7 @@ package humanize
 
 import (
 	"go/ast"
+	"go/token"
 	"strings"
 )
 
@@ -11,6 +12,17 @@ type Function struct {
 	Receiver *Variable // Nil means normal function
 	Docs     Docs
 	Type     *FuncType
+
+	Annotations Annotations
+	Directives  []*Directive
+	// Types is the local types in the function body, and its function literals
+	Types []*TypeName
+
+	decl *ast.FuncDecl
+	lit  *ast.FuncLit // for the function literals
+	file *File
+	pkg  *Package
+	body *Body
 }
 
 func compareVariable(one, two []*Variable) bool {
@@ -51,7 +63,7 @@ func compareFunc(one, two *Function) bool {
 		return false
 	}
 
-	return true
+	return one.Type.Variadic == two.Type.Variadic
 }
 
 func compare(one, two []*Function) bool {
@@ -68,6 +80,36 @@ bigLoop:
 	return true
 }
 
+// fieldGroups return the number of the names in each field
+func fieldGroups(f *ast.FieldList) []int {
+	var res []int
+	if f == nil {
+		return res
+	}
+	for i := range f.List {
+		n := len(f.List[i].Names)
+		if n == 0 {
+			n = 1
+		}
+		res = append(res, n)
+	}
+	return res
+}
+
+func newFuncType(t *ast.FuncType, def, src string, fl *File, p *Package) *FuncType {
+	res := &FuncType{
+		srcBase:         srcBase{p, def},
+		Parameters:      extractVariableList(t.Params, src, fl, p),
+		Results:         extractVariableList(t.Results, src, fl, p),
+		ParameterGroups: fieldGroups(t.Params),
+		ResultGroups:    fieldGroups(t.Results),
+	}
+	if t.Params != nil && len(t.Params.List) > 0 {
+		_, res.Variadic = t.Params.List[len(t.Params.List)-1].Type.(*ast.Ellipsis)
+	}
+	return res
+}
+
 func extractVariableList(f *ast.FieldList, src string, fl *File, p *Package) []*Variable {
 	var res []*Variable
 	if f == nil {
@@ -90,12 +132,76 @@ func extractVariableList(f *ast.FieldList, src string, fl *File, p *Package) []*
 	return res
 }
 
+// receiverType remove the type parameters of the generic receivers, T[K] is T
+func receiverType(e ast.Expr) ast.Expr {
+	switch t := e.(type) {
+	case *ast.StarExpr:
+		return &ast.StarExpr{Star: t.Star, X: receiverType(t.X)}
+	case *ast.ParenExpr:
+		return receiverType(t.X)
+	case *ast.IndexExpr:
+		return t.X
+	case *ast.IndexListExpr:
+		return t.X
+	}
+	return e
+}
+
+// generic is true for the functions with type parameters and the methods of the
+// generic types, the type parameters are not in the model
+func (f *Function) generic() bool {
+	if f.decl == nil {
+		return false
+	}
+	if f.decl.Type.TypeParams != nil {
+		return true
+	}
+	if f.decl.Recv == nil || len(f.decl.Recv.List) == 0 {
+		return false
+	}
+	e := f.decl.Recv.List[0].Type
+	for {
+		switch t := e.(type) {
+		case *ast.StarExpr:
+			e = t.X
+		case *ast.ParenExpr:
+			e = t.X
+		case *ast.IndexExpr, *ast.IndexListExpr:
+			return true
+		default:
+			return false
+		}
+	}
+}
+
+// localTypes return the types that are declared in the function body
+func localTypes(fn *Function, body *ast.BlockStmt, src string, fl *File, p *Package) []*TypeName {
+	var res []*TypeName
+	ast.Inspect(body, func(n ast.Node) bool {
+		ds, ok := n.(*ast.DeclStmt)
+		if !ok {
+			return true
+		}
+		if gd, ok := ds.Decl.(*ast.GenDecl); ok && gd.Tok == token.TYPE {
+			for _, s := range gd.Specs {
+				tn := NewType(s.(*ast.TypeSpec), gd.Doc, src, fl, p)
+				tn.Scope = fn
+				res = append(res, tn)
+			}
+		}
+		return false
+	})
+	return res
+}
+
 // NewFunction return a single function annotation
 func NewFunction(f *ast.FuncDecl, src string, fl *File, p *Package) *Function {
-	res := &Function{}
+	res := &Function{decl: f, file: fl, pkg: p}
 
 	res.Name = nameFromIdent(f.Name)
 	res.Docs = docsFromNodeDoc(f.Doc)
+	res.Annotations = annotationsFromNodeDoc(fl, f.Doc)
+	res.Directives = directivesFromNodeDoc(fl, f.Doc)
 
 	if f.Recv != nil {
 		// Method receiver is only one parameter
@@ -104,7 +210,7 @@ func NewFunction(f *ast.FuncDecl, src string, fl *File, p *Package) *Function {
 			if f.Recv.List[i].Names != nil {
 				n = nameFromIdent(f.Recv.List[i].Names[0])
 			}
-			p := variableFromExpr(n, f.Recv.List[i].Type, src, fl, p)
+			p := variableFromExpr(n, receiverType(f.Recv.List[i].Type), src, fl, p)
 			res.Receiver = p
 		}
 	}
@@ -119,10 +225,9 @@ func NewFunction(f *ast.FuncDecl, src string, fl *File, p *Package) *Function {
 		res.Name = tmp.(*IdentType).Ident + "." + res.Name
 	}
 
-	res.Type = &FuncType{
-		srcBase:    srcBase{p, ""},
-		Parameters: extractVariableList(f.Type.Params, src, fl, p),
-		Results:    extractVariableList(f.Type.Results, src, fl, p),
+	res.Type = newFuncType(f.Type, "", src, fl, p)
+	if f.Body != nil {
+		res.Types = localTypes(res, f.Body, src, fl, p)
 	}
 
 	return res
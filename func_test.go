@@ -64,7 +64,34 @@ func Some2() error {
 	return nil
 }
 
+func Variadic(p1 string, p2, p3 int, _ int, m map[string]interface{}, rest ...int) (n int, err error) {
+	return 0, nil
+}
+
+func Fn(f func(a, b int, c ...string) error) {
+}
+
+`
+
+var genericFn = `
+package test
+
+type List[T any] struct {
+	items []T
+}
+
+type Pair[K comparable, V any] struct{}
+
+func (l *List[T]) Len() int {
+	return len(l.items)
+}
 
+func (p Pair[K, V]) Key() (k K) {
+	return
+}
+
+func Use(l List[int], n int, rest ...List[string]) {
+}
 `
 
 func TestFunctionData(t *testing.T) {
@@ -192,5 +219,60 @@ func TestFunctionData(t *testing.T) {
 
 			So(compareFunc(fn1, fn2), ShouldBeFalse)
 		})
+
+		Convey("generic receivers", func() {
+			var p = &Package{}
+			f, err := ParseFile(genericFn, p)
+			So(err, ShouldBeNil)
+			p.Files = append(p.Files, f)
+
+			fn, err := p.FindFunction("List.Len")
+			So(err, ShouldBeNil)
+			So(fn.Receiver.Name, ShouldEqual, "l")
+			So(fn.Receiver.Type.(*StarType).Target.(*IdentType).Ident, ShouldEqual, "List")
+
+			fn, err = p.FindFunction("Pair.Key")
+			So(err, ShouldBeNil)
+			So(fn.Receiver.Type.(*IdentType).Ident, ShouldEqual, "Pair")
+
+			fn, err = p.FindFunction("Use")
+			So(err, ShouldBeNil)
+			So(fn.Type.Signature("Use", true), ShouldEqual, "func Use(l List[int], n int, rest ...List[string])")
+			So(fn.Type.Signature("", false), ShouldEqual, "func(List[int], int, ...List[string])")
+			So((&FuncType{Parameters: []*Variable{{Name: "x"}}}).Signature("", true), ShouldEqual, "func(x ?)")
+		})
+
+		Convey("variadic and groups", func() {
+			fn, err := p.FindFunction("Variadic")
+			So(err, ShouldBeNil)
+			So(fn.Type.Variadic, ShouldBeTrue)
+			So(fn.Type.ParameterGroups, ShouldResemble, []int{1, 2, 1, 1, 1})
+			So(fn.Type.ResultGroups, ShouldResemble, []int{1, 1})
+			last := fn.Type.Parameters[5]
+			So(last.Name, ShouldEqual, "rest")
+			So(last.Type.(*ArrayType).Slice, ShouldBeTrue)
+			So(last.Type.(*ArrayType).Type.(*IdentType).Ident, ShouldEqual, "int")
+
+			So(fn.Type.Signature("Variadic", true), ShouldEqual,
+				"func Variadic(p1 string, p2, p3 int, _ int, m map[string]interface{}, rest ...int) (n int, err error)")
+			So(fn.Type.Signature("", false), ShouldEqual,
+				"func(string, int, int, int, map[string]interface{}, ...int) (int, error)")
+
+			nr, err := p.FindFunction("NoReturn")
+			So(err, ShouldBeNil)
+			So(nr.Type.Variadic, ShouldBeFalse)
+			So(nr.Type.Signature("NoReturn", true), ShouldEqual, "func NoReturn(a int, b string, c, d int64, _ bool)")
+
+			f, err := p.FindFunction("Fn")
+			So(err, ShouldBeNil)
+			inner := f.Type.Parameters[0].Type.(*FuncType)
+			So(inner.Variadic, ShouldBeTrue)
+			So(inner.ParameterGroups, ShouldResemble, []int{2, 1})
+			So(inner.Signature("", true), ShouldEqual, "func(a, b int, c ...string) error")
+
+			fn1, err := p.FindFunction("NoReturn")
+			So(err, ShouldBeNil)
+			So(compareFunc(fn, fn1), ShouldBeFalse)
+		})
 	})
 }
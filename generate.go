@@ -0,0 +1,342 @@
+package humanize
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/token"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+// GenerateData is the data that the templates are executed with
+type GenerateData struct {
+	// Package is the first package, the one that the generator is for
+	Package *Package
+	// Packages is all the loaded packages
+	Packages []*Package
+	// Target is the *TypeName or *Function after the go:generate directive, it is nil
+	// if the generator is not called by go generate
+	Target interface{}
+}
+
+// GenerateTarget return the type or function declaration right after the line in the
+// file, the file is the base name like the GOFILE and the line is the GOLINE of the
+// go:generate directive
+func (p *Package) GenerateTarget(file string, line int) (interface{}, error) {
+	for _, f := range p.Files {
+		if filepath.Base(f.FileName) != file || f.node == nil {
+			continue
+		}
+		var (
+			res   interface{}
+			next  ast.Node
+			first int
+		)
+		check := func(n ast.Node, v interface{}) {
+			l := f.fset.Position(n.Pos()).Line
+			if l > line && (next == nil || l < first) {
+				res, next, first = v, n, l
+			}
+		}
+		for _, d := range f.node.Decls {
+			switch t := d.(type) {
+			case *ast.FuncDecl:
+				check(t, f.functionFor(t))
+			case *ast.GenDecl:
+				if !t.Lparen.IsValid() || t.Tok != token.TYPE {
+					check(t, f.typeFor(t.Specs))
+					continue
+				}
+				// the directive can be inside the group
+				for _, s := range t.Specs {
+					check(s, f.typeFor([]ast.Spec{s}))
+				}
+			}
+		}
+		if next == nil {
+			return nil, fmt.Errorf("no declaration after line %d in %s", line, file)
+		}
+		if res == nil {
+			return nil, fmt.Errorf("the declaration after line %d in %s is not a type or function", line, file)
+		}
+		return res, nil
+	}
+	return nil, fmt.Errorf("file %s is not in package %s", file, p.Path)
+}
+
+// typeFor return the type of the first spec, if it is a type spec
+func (f *File) typeFor(specs []ast.Spec) interface{} {
+	if len(specs) == 0 {
+		return nil
+	}
+	ts, ok := specs[0].(*ast.TypeSpec)
+	if !ok {
+		return nil
+	}
+	for _, tn := range f.Types {
+		if tn.Name == ts.Name.Name {
+			return tn
+		}
+	}
+	return nil
+}
+
+// Generator execute the text/template files against the model and create a gofmt-ed
+// go file. the template output is the declarations, the header, the package clause
+// and the imports are added by the generator
+type Generator struct {
+	// Name is the name of the generator, it is in the "Code generated" header
+	Name string
+	// PackageName is the package name of the output file
+	PackageName string
+	// Imports is the imports of the output, the Imports.Package is the import path of
+	// the output package, the types in that package are without the package name
+	Imports *ImportSet
+
+	tmpl *template.Template
+	main string // the name of the first template
+}
+
+// NewGenerator return a new generator for the output package
+func NewGenerator(name, pkgName string) *Generator {
+	g := &Generator{
+		Name:        name,
+		PackageName: pkgName,
+		Imports:     NewImportSet(),
+	}
+	g.tmpl = template.New(name).Funcs(g.Funcs())
+	return g
+}
+
+// Parse add a template to the generator, the first one is the one that is executed
+// and the others can be used with the template action
+func (g *Generator) Parse(name, text string) error {
+	if g.main == "" {
+		g.main = name
+	}
+	_, err := g.tmpl.New(name).Parse(text)
+	return err
+}
+
+// ParseFiles add the template files to the generator, the template name is the base
+// name of the file
+func (g *Generator) ParseFiles(files ...string) error {
+	for _, fl := range files {
+		data, err := os.ReadFile(fl)
+		if err != nil {
+			return err
+		}
+		if err := g.Parse(filepath.Base(fl), string(data)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Execute run the main template and write the go file
+func (g *Generator) Execute(w io.Writer, data *GenerateData) error {
+	if g.main == "" {
+		return fmt.Errorf("generator %s has no template", g.Name)
+	}
+	body := &bytes.Buffer{}
+	if err := g.tmpl.ExecuteTemplate(body, g.main, data); err != nil {
+		return err
+	}
+
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "// Code generated by %s. DO NOT EDIT.\n\n", g.Name)
+	fmt.Fprintf(buf, "package %s\n\n", g.PackageName)
+	if imports := g.Imports.Source(); imports != "" {
+		buf.WriteString(imports + "\n")
+	}
+	buf.Write(body.Bytes())
+
+	res, err := format.Source(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("the generated code is invalid: %w", err)
+	}
+	_, err = w.Write(res)
+	return err
+}
+
+// Funcs return the functions that are available in the templates:
+//
+//   - type: the go source of a Type, or the declaration of a TypeName, Function,
+//     Variable or Constant. the package names are from the import set
+//   - qualify: the qualified name, like {{ qualify "net/http" "Handler" }}, it adds the
+//     import too
+//   - exported: only the exported items of the list, like {{ range exported .Types }}
+//   - annotation: the first annotation with the name, like {{ annotation "Route" . }}
+//   - annotations: all the annotations with the name
+//   - implements: check if the type (or its pointer) implements the interface
+//   - lower, upper, camel, pascal and snake: the name helpers
+func (g *Generator) Funcs() template.FuncMap {
+	return template.FuncMap{
+		"type": func(v interface{}) (string, error) {
+			return NewPrinter(g.Imports).Sprint(v)
+		},
+		"qualify": func(path, name string) string {
+			if q := g.Imports.Qualifier(path); q != "" {
+				return q + "." + name
+			}
+			return name
+		},
+		"exported":    exported,
+		"annotation":  findAnnotation,
+		"annotations": findAnnotations,
+		"implements":  implements,
+		"lower":       strings.ToLower,
+		"upper":       strings.ToUpper,
+		"camel":       camelCase,
+		"pascal":      pascalCase,
+		"snake":       snakeCase,
+	}
+}
+
+// exported return the items in the slice with an exported Name
+func exported(list interface{}) (interface{}, error) {
+	v := reflect.ValueOf(list)
+	if v.Kind() != reflect.Slice {
+		return nil, fmt.Errorf("exported needs a slice, not %T", list)
+	}
+	res := reflect.MakeSlice(v.Type(), 0, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		item := reflect.Indirect(v.Index(i))
+		if item.Kind() != reflect.Struct {
+			return nil, fmt.Errorf("exported needs a slice of structs, not %T", list)
+		}
+		name := item.FieldByName("Name")
+		if !name.IsValid() || name.Kind() != reflect.String {
+			return nil, fmt.Errorf("%s has no name", item.Type())
+		}
+		// the methods are receiver.name
+		if isExported(removeReceiver(name.String())) {
+			res = reflect.Append(res, v.Index(i))
+		}
+	}
+	return res.Interface(), nil
+}
+
+func isExported(name string) bool {
+	for _, r := range name {
+		return unicode.IsUpper(r)
+	}
+	return false
+}
+
+func annotationsOf(v interface{}) (Annotations, error) {
+	switch x := v.(type) {
+	case Annotations:
+		return x, nil
+	case *TypeName:
+		return x.Annotations, nil
+	case *Function:
+		return x.Annotations, nil
+	case *Variable:
+		return x.Annotations, nil
+	case *Field:
+		return x.Annotations, nil
+	case *Constant:
+		return x.Annotations, nil
+	case *File:
+		return x.Annotations, nil
+	}
+	return nil, fmt.Errorf("%T has no annotations", v)
+}
+
+func findAnnotation(name string, v interface{}) (*Annotation, error) {
+	a, err := annotationsOf(v)
+	if err != nil {
+		return nil, err
+	}
+	res, _ := a.Find(name)
+	return res, nil
+}
+
+func findAnnotations(name string, v interface{}) (Annotations, error) {
+	a, err := annotationsOf(v)
+	if err != nil {
+		return nil, err
+	}
+	return a.FindAll(name), nil
+}
+
+// implements check the method set of the pointer of the type, the interface can be
+// an InterfaceType or a TypeName of an interface
+func implements(tn *TypeName, iface interface{}) (bool, error) {
+	var in *InterfaceType
+	switch x := iface.(type) {
+	case *InterfaceType:
+		in = x
+	case *TypeName:
+		in, _ = x.Type.(*InterfaceType)
+	}
+	if in == nil {
+		return false, fmt.Errorf("%v is not an interface", iface)
+	}
+	return supports(tn, in), nil
+}
+
+// words split the name into the words, HTTPServer_name is HTTP, Server and name
+func words(s string) []string {
+	var (
+		res []string
+		cur []rune
+	)
+	rs := []rune(s)
+	for i, r := range rs {
+		if r == '_' || r == '-' || r == ' ' || r == '.' {
+			if len(cur) > 0 {
+				res = append(res, string(cur))
+			}
+			cur = nil
+			continue
+		}
+		if len(cur) > 0 && unicode.IsUpper(r) {
+			prev := cur[len(cur)-1]
+			nextLower := i+1 < len(rs) && unicode.IsLower(rs[i+1])
+			if !unicode.IsUpper(prev) || nextLower {
+				res = append(res, string(cur))
+				cur = nil
+			}
+		}
+		cur = append(cur, r)
+	}
+	if len(cur) > 0 {
+		res = append(res, string(cur))
+	}
+	return res
+}
+
+func snakeCase(s string) string {
+	w := words(s)
+	for i := range w {
+		w[i] = strings.ToLower(w[i])
+	}
+	return strings.Join(w, "_")
+}
+
+func pascalCase(s string) string {
+	w := words(s)
+	for i := range w {
+		rs := []rune(strings.ToLower(w[i]))
+		rs[0] = unicode.ToUpper(rs[0])
+		w[i] = string(rs)
+	}
+	return strings.Join(w, "")
+}
+
+func camelCase(s string) string {
+	res := []rune(pascalCase(s))
+	if len(res) > 0 {
+		res[0] = unicode.ToLower(res[0])
+	}
+	return string(res)
+}
@@ -0,0 +1,184 @@
+package humanize
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+var genSrc = `package test
+
+import "net/http"
+
+// Stringer is the stringer
+type Stringer interface {
+	String() string
+}
+
+// User is a user
+// @Builder prefix=With
+type User struct {
+	Name    string
+	Age     int
+	Handler http.Handler
+	secret  string
+}
+
+func (u *User) String() string {
+	return u.Name
+}
+
+type hidden struct{}
+
+// Point is a point
+type Point struct {
+	X, Y int
+}
+`
+
+var genTemplate = `{{ range .Package.Files }}{{ range exported .Types }}{{ if annotation "Builder" . }}
+{{ $prefix := (annotation "Builder" .).Params.prefix }}
+// {{ .Name }}Builder is the builder for {{ .Name }}
+type {{ .Name }}Builder struct {
+	v {{ .Name }}
+}
+{{ $tn := . }}{{ range exported .Type.Fields }}
+// {{ $prefix }}{{ pascal .Name }} set the {{ snake .Name }}
+func (b *{{ $tn.Name }}Builder) {{ $prefix }}{{ pascal .Name }}({{ camel .Name }} {{ type .Type }}) *{{ $tn.Name }}Builder {
+	b.v.{{ .Name }} = {{ camel .Name }}
+	return b
+}
+{{ end }}{{ end }}{{ end }}{{ end -}}
+{{ template "extra.tmpl" . }}
+`
+
+var targetSrc = `package test
+
+//go:generate humanize gen -t builder.tmpl
+type A struct{}
+
+// B is the b
+//go:generate humanize gen -t builder.tmpl
+func B() {}
+
+//go:generate humanize gen -t builder.tmpl
+
+var c int
+
+//go:generate humanize gen -t builder.tmpl
+type (
+	D int
+	//go:generate humanize gen -t builder.tmpl
+	E int
+)
+
+//go:generate humanize gen -t builder.tmpl
+`
+
+func TestGenerateTarget(t *testing.T) {
+	Convey("go generate target", t, func() {
+		var p = &Package{Path: "example.com/test", Name: "test"}
+		f, err := parseFile("/tmp/test/target.go", targetSrc, p)
+		So(err, ShouldBeNil)
+		p.Files = append(p.Files, f)
+
+		res, err := p.GenerateTarget("target.go", 3)
+		So(err, ShouldBeNil)
+		So(res.(*TypeName).Name, ShouldEqual, "A")
+
+		res, err = p.GenerateTarget("target.go", 7)
+		So(err, ShouldBeNil)
+		So(res.(*Function).Name, ShouldEqual, "B")
+
+		_, err = p.GenerateTarget("target.go", 10)
+		So(err, ShouldNotBeNil)
+
+		res, err = p.GenerateTarget("target.go", 14)
+		So(err, ShouldBeNil)
+		So(res.(*TypeName).Name, ShouldEqual, "D")
+
+		res, err = p.GenerateTarget("target.go", 17)
+		So(err, ShouldBeNil)
+		So(res.(*TypeName).Name, ShouldEqual, "E")
+
+		_, err = p.GenerateTarget("target.go", 21)
+		So(err, ShouldNotBeNil)
+		_, err = p.GenerateTarget("other.go", 3)
+		So(err, ShouldNotBeNil)
+	})
+}
+
+func TestGenerator(t *testing.T) {
+	Convey("Generator test", t, func() {
+		var p = &Package{Path: "example.com/test", Name: "test"}
+		f, err := ParseFile(genSrc, p)
+		So(err, ShouldBeNil)
+		p.Files = append(p.Files, f)
+		So(lateBind(p), ShouldBeNil)
+
+		Convey("name helpers", func() {
+			So(words("HTTPServer_name"), ShouldResemble, []string{"HTTP", "Server", "name"})
+			So(snakeCase("UserID"), ShouldEqual, "user_id")
+			So(camelCase("user_id"), ShouldEqual, "userId")
+			So(pascalCase("http-server"), ShouldEqual, "HttpServer")
+			So(isExported("Name"), ShouldBeTrue)
+			So(isExported("name"), ShouldBeFalse)
+		})
+
+		Convey("functions", func() {
+			res, err := exported(f.Types)
+			So(err, ShouldBeNil)
+			So(len(res.([]*TypeName)), ShouldEqual, 3)
+			_, err = exported(42)
+			So(err, ShouldNotBeNil)
+
+			user, err := p.FindType("User")
+			So(err, ShouldBeNil)
+			a, err := findAnnotation("Builder", user)
+			So(err, ShouldBeNil)
+			So(a.Params["prefix"], ShouldEqual, "With")
+			_, err = findAnnotation("Builder", 42)
+			So(err, ShouldNotBeNil)
+
+			str, err := p.FindType("Stringer")
+			So(err, ShouldBeNil)
+			ok, err := implements(user, str)
+			So(err, ShouldBeNil)
+			So(ok, ShouldBeTrue)
+			point, err := p.FindType("Point")
+			So(err, ShouldBeNil)
+			ok, err = implements(point, str)
+			So(err, ShouldBeNil)
+			So(ok, ShouldBeFalse)
+			_, err = implements(point, point)
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("execute", func() {
+			g := NewGenerator("humanize gen", "other")
+			So(g.Execute(&bytes.Buffer{}, nil), ShouldNotBeNil)
+
+			dir := t.TempDir()
+			main := filepath.Join(dir, "builder.tmpl")
+			So(os.WriteFile(main, []byte(genTemplate), 0644), ShouldBeNil)
+			extra := filepath.Join(dir, "extra.tmpl")
+			So(os.WriteFile(extra, []byte(`var _ = {{ qualify "example.com/test" "User" }}{}`), 0644), ShouldBeNil)
+			So(g.ParseFiles(main, extra), ShouldBeNil)
+
+			buf := &bytes.Buffer{}
+			So(g.Execute(buf, &GenerateData{Package: p, Packages: []*Package{p}}), ShouldBeNil)
+			So(buf.String(), ShouldStartWith, "// Code generated by humanize gen. DO NOT EDIT.\n\npackage other\n\nimport (\n\t\"net/http\"\n\n\t\"example.com/test\"\n)\n")
+			So(buf.String(), ShouldContainSubstring, "// WithName set the name\nfunc (b *UserBuilder) WithName(name string) *UserBuilder {\n\tb.v.Name = name\n\treturn b\n}\n")
+			So(buf.String(), ShouldContainSubstring, "func (b *UserBuilder) WithHandler(handler http.Handler) *UserBuilder {")
+			So(buf.String(), ShouldNotContainSubstring, "Secret")
+			So(buf.String(), ShouldEndWith, "\nvar _ = test.User{}\n")
+
+			g = NewGenerator("humanize gen", "other")
+			So(g.Parse("bad", "func {"), ShouldBeNil)
+			So(g.Execute(&bytes.Buffer{}, &GenerateData{}), ShouldNotBeNil)
+		})
+	})
+}
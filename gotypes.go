@@ -0,0 +1,241 @@
+package humanize
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"reflect"
+)
+
+// typesConverter convert the go/types types into the humanize types, the pkg
+// is the package that the types are used in, and file is used for finding the
+// imports for selector types
+type typesConverter struct {
+	pkg  *Package
+	file *File
+}
+
+func (tc *typesConverter) base() srcBase {
+	return srcBase{pkg: tc.pkg}
+}
+
+func (tc *typesConverter) ident(name string) *IdentType {
+	return &IdentType{srcBase: tc.base(), Ident: name}
+}
+
+func (tc *typesConverter) importFor(p *types.Package) *Import {
+	if tc.file != nil {
+		for _, i := range tc.file.Imports {
+			if i.Path == p.Path() {
+				return i
+			}
+		}
+	}
+	return &Import{Name: p.Name(), Path: p.Path()}
+}
+
+func (tc *typesConverter) named(obj *types.TypeName) Type {
+	if obj.Pkg() == nil || (tc.pkg != nil && obj.Pkg().Path() == tc.pkg.Path) {
+		return tc.ident(obj.Name())
+	}
+	return &SelectorType{
+		srcBase: tc.base(),
+		pkg:     tc.importFor(obj.Pkg()),
+		Type:    &IdentType{Ident: obj.Name()},
+	}
+}
+
+func (tc *typesConverter) tuple(t *types.Tuple) []*Variable {
+	var res []*Variable
+	for i := 0; i < t.Len(); i++ {
+		res = append(res, &Variable{Name: t.At(i).Name(), Type: tc.convert(t.At(i).Type())})
+	}
+	return res
+}
+
+func (tc *typesConverter) signature(t *types.Signature) *FuncType {
+	return &FuncType{
+		srcBase:    tc.base(),
+		Parameters: tc.tuple(t.Params()),
+		Results:    tc.tuple(t.Results()),
+		Variadic:   t.Variadic(),
+	}
+}
+
+func (tc *typesConverter) method(f *types.Func) *Function {
+	return &Function{Name: f.Name(), Type: tc.signature(f.Type().(*types.Signature))}
+}
+
+// convert return the humanize type of a go/types type
+func (tc *typesConverter) convert(t types.Type) Type {
+	switch x := t.(type) {
+	case *types.Basic:
+		return tc.ident(types.Default(x).(*types.Basic).Name())
+	case *types.Alias:
+		return tc.named(x.Obj())
+	case *types.Named:
+		return tc.named(x.Obj())
+	case *types.TypeParam:
+		return tc.ident(x.Obj().Name())
+	case *types.Pointer:
+		return &StarType{srcBase: tc.base(), Target: tc.convert(x.Elem())}
+	case *types.Slice:
+		return &ArrayType{srcBase: tc.base(), Slice: true, Type: tc.convert(x.Elem())}
+	case *types.Array:
+		return &ArrayType{srcBase: tc.base(), Len: int(x.Len()), Type: tc.convert(x.Elem())}
+	case *types.Map:
+		return &MapType{srcBase: tc.base(), Key: tc.convert(x.Key()), Value: tc.convert(x.Elem())}
+	case *types.Chan:
+		dir := ast.SEND | ast.RECV
+		switch x.Dir() {
+		case types.SendOnly:
+			dir = ast.SEND
+		case types.RecvOnly:
+			dir = ast.RECV
+		}
+		return &ChannelType{srcBase: tc.base(), Direction: dir, Type: tc.convert(x.Elem())}
+	case *types.Signature:
+		return tc.signature(x)
+	case *types.Struct:
+		res := &StructType{srcBase: tc.base()}
+		for i := 0; i < x.NumFields(); i++ {
+			f := x.Field(i)
+			tag := reflect.StructTag(x.Tag(i))
+			if f.Embedded() {
+				res.Embeds = append(res.Embeds, &Embed{Type: tc.convert(f.Type()), Tags: tag})
+				continue
+			}
+			res.Fields = append(res.Fields, &Field{
+				Variable: Variable{Name: f.Name(), Type: tc.convert(f.Type())},
+				Tags:     tag,
+			})
+		}
+		return res
+	case *types.Interface:
+		res := &InterfaceType{srcBase: tc.base()}
+		for i := 0; i < x.NumExplicitMethods(); i++ {
+			res.Functions = append(res.Functions, tc.method(x.ExplicitMethod(i)))
+		}
+		for i := 0; i < x.NumEmbeddeds(); i++ {
+			res.Embed = append(res.Embed, tc.convert(x.EmbeddedType(i)))
+		}
+		return res
+	}
+	return nil
+}
+
+// typedMethods return the method set of the type, base on the go/types
+func (tn TypeName) typedMethods(pointer bool) []*Function {
+	var t types.Type = tn.obj.Type()
+	if pointer {
+		t = types.NewPointer(t)
+	}
+	p := tn.Type.Package()
+	tc := &typesConverter{pkg: p}
+	ms := types.NewMethodSet(t)
+	var res []*Function
+	for i := 0; i < ms.Len(); i++ {
+		fn := ms.At(i).Obj().(*types.Func)
+		// the name is base on the type that the method is declared on, like the
+		// promoted method of the embedded types
+		name := fn.Name()
+		if recv := fn.Type().(*types.Signature).Recv(); recv != nil {
+			rt := recv.Type()
+			if ptr, ok := rt.(*types.Pointer); ok {
+				rt = ptr.Elem()
+			}
+			if named, ok := rt.(*types.Named); ok {
+				name = named.Obj().Name() + "." + name
+				if p != nil && named.Obj().Pkg() == p.types {
+					if d, err := p.FindFunction(name); err == nil {
+						res = append(res, d)
+						continue
+					}
+				}
+			}
+		}
+		m := tc.method(fn)
+		m.Name = name
+		res = append(res, m)
+	}
+	return res
+}
+
+// typeCheck run the go/types type checker on the package files, and then fill the
+// types of the variables and constants, and the values of the constants base on it
+func typeCheck(p *Package) {
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for _, f := range p.Files {
+		af, err := parser.ParseFile(fset, f.FileName, f.src, parser.ParseComments)
+		if err != nil {
+			continue
+		}
+		files = append(files, af)
+	}
+
+	conf := types.Config{
+		Importer: importer.ForCompiler(fset, "source", nil),
+		Error: func(err error) {
+			d := Diagnostic{Message: err.Error()}
+			if te, ok := err.(types.Error); ok {
+				d.Pos = te.Fset.Position(te.Pos)
+				d.Message = te.Msg
+			}
+			p.Diagnostics = append(p.Diagnostics, d)
+		},
+	}
+	// the errors are in the diagnostics, the package is usable even if there is an error
+	pkg, _ := conf.Check(p.Path, fset, files, nil)
+	if pkg == nil {
+		return
+	}
+	p.types = pkg
+
+	scope := pkg.Scope()
+	for _, f := range p.Files {
+		tc := &typesConverter{pkg: p, file: f}
+		for _, v := range f.Variables {
+			obj, ok := scope.Lookup(v.Name).(*types.Var)
+			if !ok || v.typeExpr != nil {
+				continue
+			}
+			v.Type = tc.convert(obj.Type())
+		}
+		for _, c := range f.Constants {
+			obj, ok := scope.Lookup(c.Name).(*types.Const)
+			if !ok {
+				continue
+			}
+			if c.typeExpr == nil {
+				c.Type = tc.convert(obj.Type())
+			}
+			c.val = obj.Val()
+		}
+		for _, t := range f.Types {
+			if obj, ok := scope.Lookup(t.Name).(*types.TypeName); ok {
+				t.obj = obj
+			}
+		}
+	}
+}
+
+// ParsePackageWithTypes load the package like the ParsePackage, but the types of the
+// variables, constants values and method sets are from the go/types type checker, using
+// the source importer. the type errors are reported in the package diagnostics
+func ParsePackageWithTypes(path string) (*Package, error) {
+	key := "types:" + path
+	if p := getCache(key); p != nil {
+		return p, nil
+	}
+	p, err := parseFiles(path)
+	if err != nil {
+		return nil, err
+	}
+	setCache(key, p)
+	findMethods(p)
+	typeCheck(p)
+	return p, nil
+}
@@ -0,0 +1,115 @@
+package humanize
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+var typed = `
+package test
+
+import (
+	"os"
+	"strings"
+)
+
+type Base struct{}
+
+func (Base) Close() error {
+	return nil
+}
+
+type Derived struct {
+	Base
+	*strings.Builder
+}
+
+func (d *Derived) Own() {}
+
+type Color uint8
+
+const (
+	Red Color = 1 << iota
+	Green
+)
+
+const big = 1 << 10
+
+var (
+	file, ferr = os.Open("x")
+	reader     = strings.NewReader("x").Len
+	ch         = make(chan<- Color)
+	items      = map[Color][]*Derived{}
+	char       = 'c'
+	wrong      int = "string"
+)
+`
+
+func TestGoTypes(t *testing.T) {
+	Convey("go/types backend", t, func() {
+		var p = &Package{Path: "example.com/test"}
+		f, err := ParseFile(typed, p)
+		So(err, ShouldBeNil)
+		f.FileName = "typed.go"
+		p.Files = append(p.Files, f)
+		findMethods(p)
+		typeCheck(p)
+		So(p.types, ShouldNotBeNil)
+
+		Convey("variables", func() {
+			types := map[string]string{
+				"file":   "*os.File",
+				"ferr":   "error",
+				"reader": "func () int",
+				"ch":     "chan<- Color",
+				"items":  "map[Color][]*Derived",
+				"char":   "rune",
+				"wrong":  "int",
+			}
+			for name, def := range types {
+				v, err := p.FindVariable(name)
+				So(err, ShouldBeNil)
+				So(v.Type.GetDefinition(), ShouldEqual, def)
+			}
+			v, err := p.FindVariable("file")
+			So(err, ShouldBeNil)
+			So(v.Type.(*StarType).Target.(*SelectorType).pkg.Path, ShouldEqual, "os")
+		})
+
+		Convey("constants", func() {
+			c, err := p.FindConstant("Green")
+			So(err, ShouldBeNil)
+			So(c.Type.GetDefinition(), ShouldEqual, "Color")
+			v, err := c.Evaluate()
+			So(err, ShouldBeNil)
+			So(v.String(), ShouldEqual, "2")
+
+			c, err = p.FindConstant("big")
+			So(err, ShouldBeNil)
+			So(c.Type.GetDefinition(), ShouldEqual, "int")
+		})
+
+		Convey("method sets", func() {
+			tn, err := p.FindType("Derived")
+			So(err, ShouldBeNil)
+			names := func(fns []*Function) []string {
+				var res []string
+				for i := range fns {
+					res = append(res, fns[i].Name)
+				}
+				return res
+			}
+			So(names(tn.GetAllMethods(false)), ShouldContain, "Base.Close")
+			So(names(tn.GetAllMethods(false)), ShouldNotContain, "Derived.Own")
+			So(names(tn.GetAllMethods(true)), ShouldContain, "Derived.Own")
+			So(names(tn.GetAllMethods(true)), ShouldContain, "Builder.WriteString")
+		})
+
+		Convey("diagnostics", func() {
+			So(len(p.Diagnostics), ShouldEqual, 1)
+			So(p.Diagnostics[0].Pos.Filename, ShouldEqual, "typed.go")
+			So(p.Diagnostics[0].Pos.Line, ShouldEqual, 37)
+		})
+	})
+}
@@ -0,0 +1,458 @@
+package humanize
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// GraphQLOptions is the options of the GraphQL export
+type GraphQLOptions struct {
+	// Inputs is the names of the structs that are input types, the structs with the
+	// @Input annotation are input types too
+	Inputs []string
+	// Methods add the exported methods without arguments to the object types, the
+	// result can be T or (T, error)
+	Methods bool
+}
+
+var gqlBasic = map[string]string{
+	"string": "String", "bool": "Boolean",
+	"int": "Int", "int8": "Int", "int16": "Int", "int32": "Int", "int64": "Int",
+	"uint": "Int", "uint8": "Int", "uint16": "Int", "uint32": "Int", "uint64": "Int",
+	"byte": "Int", "rune": "Int",
+	"float32": "Float", "float64": "Float",
+}
+
+// gqlScalars is the custom scalars, they are declared if they are used
+var gqlScalars = map[string]string{
+	"time.Time":                "Time",
+	"encoding/json.RawMessage": "JSON",
+}
+
+type gqlField struct {
+	Name string
+	Type string
+	Docs Docs
+}
+
+type gqlDecl struct {
+	Kind       string // type, input, interface or enum
+	Name       string
+	Docs       Docs
+	Fields     []*gqlField
+	Implements []string
+
+	tn *TypeName
+	p  *Package
+}
+
+// gqlBuilder create the declarations, the named types in the fields are added too
+type gqlBuilder struct {
+	opts    GraphQLOptions
+	pkg     *Package
+	decls   map[*TypeName]*gqlDecl
+	names   map[string]*TypeName
+	queue   []*gqlDecl
+	scalars map[string]bool
+}
+
+// GraphQL create the GraphQL schema of the types in the package, all the exported
+// structs, interfaces and enums if there is no name. the structs are object types, or
+// input types, the interfaces are interfaces and the structs in the package that
+// implement them are added too. the field names are from the graphql or json tags, or
+// the camel case of the go name, and the pointers and the omitempty fields are
+// nullable
+func GraphQL(p *Package, opts GraphQLOptions, names ...string) (string, error) {
+	gb := &gqlBuilder{
+		opts:    opts,
+		pkg:     p,
+		decls:   make(map[*TypeName]*gqlDecl),
+		names:   make(map[string]*TypeName),
+		scalars: make(map[string]bool),
+	}
+	var roots []*TypeName
+	for _, name := range names {
+		tn, err := p.FindType(name)
+		if err != nil {
+			return "", err
+		}
+		roots = append(roots, tn)
+	}
+	if len(names) == 0 {
+		for _, f := range p.Files {
+			for _, tn := range f.Types {
+				if !isExported(tn.Name) {
+					continue
+				}
+				switch tn.Type.(type) {
+				case *StructType, *InterfaceType:
+					roots = append(roots, tn)
+					continue
+				}
+				if _, err := p.FindEnum(tn.Name); err == nil {
+					roots = append(roots, tn)
+				}
+			}
+		}
+	}
+	for _, tn := range roots {
+		if _, err := gb.ref(tn, p, gb.isInput(tn)); err != nil {
+			return "", err
+		}
+		if _, ok := gb.decls[tn]; !ok {
+			return "", fmt.Errorf("type %s is not a struct, an interface or an enum", tn.Name)
+		}
+	}
+	for i := 0; i < len(gb.queue); i++ {
+		d := gb.queue[i]
+		if err := gb.fields(d); err != nil {
+			return "", fmt.Errorf("type %s: %w", d.tn.Name, err)
+		}
+	}
+	gb.implements()
+	return gb.source(), nil
+}
+
+func (gb *gqlBuilder) isInput(tn *TypeName) bool {
+	if _, ok := tn.Annotations.Find("Input"); ok {
+		return true
+	}
+	for _, name := range gb.opts.Inputs {
+		if name == tn.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// ref return the name of the named type, and add the declaration if it is not there
+func (gb *gqlBuilder) ref(tn *TypeName, p *Package, input bool) (string, error) {
+	if d, ok := gb.decls[tn]; ok {
+		if (d.Kind == "type" && input) || (d.Kind == "input" && !input) {
+			return "", fmt.Errorf("type %s is used as an input and an output type", tn.Name)
+		}
+		if d.Kind == "interface" && input {
+			return "", fmt.Errorf("interface %s can not be an input type", tn.Name)
+		}
+		return d.Name, nil
+	}
+	d := &gqlDecl{Name: tn.Name, Docs: tn.Docs, tn: tn, p: p}
+	if _, err := p.FindEnum(tn.Name); err == nil {
+		d.Kind = "enum"
+	} else {
+		switch x := tn.Type.(type) {
+		case *StructType:
+			d.Kind = "type"
+			if input {
+				d.Kind = "input"
+			}
+		case *InterfaceType:
+			if len(x.Functions) == 0 && len(x.Embed) == 0 {
+				return gb.scalar("JSON"), nil
+			}
+			if input {
+				return "", fmt.Errorf("interface %s can not be an input type", tn.Name)
+			}
+			d.Kind = "interface"
+		default:
+			return gb.base(tn.Type, p, input)
+		}
+	}
+	if other, ok := gb.names[d.Name]; ok && other != tn {
+		return "", fmt.Errorf("there is more than one type with the name %s", d.Name)
+	}
+	gb.names[d.Name] = tn
+	gb.decls[tn] = d
+	gb.queue = append(gb.queue, d)
+	return d.Name, nil
+}
+
+func (gb *gqlBuilder) scalar(name string) string {
+	gb.scalars[name] = true
+	return name
+}
+
+// typ return the type of the field, it is non-null if it is not a pointer and it is
+// not nullable
+func (gb *gqlBuilder) typ(t Type, p *Package, input, nullable bool) (string, error) {
+	if s, ok := t.(*StarType); ok {
+		nullable = true
+		t = s.Target
+	}
+	t, p = namedCollection(t, p)
+	if x, ok := t.(*EllipsisType); ok {
+		t = x.ArrayType
+	}
+	var (
+		res string
+		err error
+	)
+	if a, ok := t.(*ArrayType); ok && !isBytes(a) {
+		res, err = gb.typ(a.Type, p, input, false)
+		res = "[" + res + "]"
+	} else {
+		res, err = gb.base(t, p, input)
+	}
+	if err != nil {
+		return "", err
+	}
+	if !nullable {
+		res += "!"
+	}
+	return res, nil
+}
+
+// base return the name of the type, without the list and the non-null
+func (gb *gqlBuilder) base(t Type, p *Package, input bool) (string, error) {
+	switch x := t.(type) {
+	case *IdentType:
+		if s, ok := gqlBasic[x.Ident]; ok {
+			return s, nil
+		}
+		if x.Ident == "any" {
+			return gb.scalar("JSON"), nil
+		}
+		if predeclared[x.Ident] {
+			return "", fmt.Errorf("%s can not be mapped", x.Ident)
+		}
+	case *SelectorType:
+		if x.pkg != nil {
+			name := x.pkg.Path + "." + x.Type.GetDefinition()
+			if s, ok := gqlScalars[name]; ok {
+				return gb.scalar(s), nil
+			}
+			if name == "time.Duration" {
+				return "Int", nil
+			}
+		}
+	case *StarType:
+		return gb.base(x.Target, p, input)
+	case *ArrayType:
+		if isBytes(x) {
+			return "String", nil
+		}
+		return "", fmt.Errorf("list %s can not be mapped here", goSource(x))
+	case *MapType:
+		// there is no map in GraphQL
+		return gb.scalar("JSON"), nil
+	case *InterfaceType:
+		if len(x.Functions) == 0 && len(x.Embed) == 0 {
+			return gb.scalar("JSON"), nil
+		}
+		return "", fmt.Errorf("anonymous interface can not be mapped")
+	case *StructType:
+		return "", fmt.Errorf("anonymous struct can not be mapped")
+	default:
+		return "", fmt.Errorf("%s can not be mapped", goSource(t))
+	}
+	tn, tp := newTyper(p, nil).typeName(t)
+	if tn == nil {
+		return "", fmt.Errorf("type %s not found", goSource(t))
+	}
+	return gb.ref(tn, tp, input)
+}
+
+// resolver return the result of the method if it can be a field, a single result or
+// a result and an error without arguments
+func resolver(fn *Function) Type {
+	if fn.Type == nil || len(fn.Type.Parameters) != 0 {
+		return nil
+	}
+	switch len(fn.Type.Results) {
+	case 1:
+		return fn.Type.Results[0].Type
+	case 2:
+		if id, ok := fn.Type.Results[1].Type.(*IdentType); ok && id.Ident == "error" {
+			return fn.Type.Results[0].Type
+		}
+	}
+	return nil
+}
+
+// fields add the fields of the declaration
+func (gb *gqlBuilder) fields(d *gqlDecl) error {
+	switch d.Kind {
+	case "enum":
+		e, err := d.p.FindEnum(d.tn.Name)
+		if err != nil {
+			return err
+		}
+		seen := make(map[string]bool)
+		for _, v := range e.Values {
+			if v.Constant.Name == "_" {
+				continue
+			}
+			name := v.Constant.Name
+			if n := strings.TrimPrefix(name, d.tn.Name); n != "" {
+				name = n
+			}
+			name = strings.ToUpper(snakeCase(name))
+			if !seen[name] {
+				seen[name] = true
+				d.Fields = append(d.Fields, &gqlField{Name: name, Docs: v.Constant.Docs})
+			}
+		}
+		if len(d.Fields) == 0 {
+			return fmt.Errorf("enum without values")
+		}
+		return nil
+	case "interface":
+		in := d.tn.Type.(*InterfaceType)
+		for _, fn := range in.Functions {
+			t := resolver(fn)
+			if t == nil || !isExported(fn.Name) {
+				continue
+			}
+			typ, err := gb.typ(t, d.p, false, false)
+			if err != nil {
+				return fmt.Errorf("method %s: %w", fn.Name, err)
+			}
+			d.Fields = append(d.Fields, &gqlField{Name: camelCase(fn.Name), Type: typ, Docs: fn.Docs})
+		}
+		// the implementations in the package
+		for _, f := range gb.pkg.Files {
+			for _, tn := range f.Types {
+				if _, ok := tn.Type.(*StructType); ok && isExported(tn.Name) && supports(tn, in) {
+					if _, err := gb.ref(tn, gb.pkg, false); err != nil {
+						return err
+					}
+				}
+			}
+		}
+		return nil
+	}
+
+	input := d.Kind == "input"
+	fields, err := structFields(d.tn.Type.(*StructType), d.p, "json")
+	if err != nil {
+		return err
+	}
+	seen := make(map[string]bool)
+	for _, wf := range fields {
+		name := wf.Name
+		if jn, _ := jsonTag(wf.Tags.Get("json")); jn == "" {
+			name = camelCase(name)
+		}
+		if gn, _ := jsonTag(wf.Tags.Get("graphql")); gn == "-" {
+			continue
+		} else if gn != "" {
+			name = gn
+		}
+		typ, err := gb.typ(wf.Type, wf.Package, input, wf.OmitEmpty)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", wf.Name, err)
+		}
+		seen[name] = true
+		d.Fields = append(d.Fields, &gqlField{Name: name, Type: typ, Docs: wf.Docs})
+	}
+	if input || !gb.opts.Methods {
+		return nil
+	}
+	for _, fn := range append(append([]*Function{}, d.tn.Methods...), d.tn.StarMethods...) {
+		name := removeReceiver(fn.Name)
+		t := resolver(fn)
+		if t == nil || !isExported(name) || name == "String" || name == "Error" || seen[camelCase(name)] {
+			continue
+		}
+		typ, err := gb.typ(t, d.p, false, false)
+		if err != nil {
+			return fmt.Errorf("method %s: %w", name, err)
+		}
+		seen[camelCase(name)] = true
+		d.Fields = append(d.Fields, &gqlField{Name: camelCase(name), Type: typ, Docs: fn.Docs})
+	}
+	return nil
+}
+
+// implements set the interfaces of the object types, the fields of the interface
+// that are not in the object are resolver fields
+func (gb *gqlBuilder) implements() {
+	var ifaces []*gqlDecl
+	for _, d := range gb.decls {
+		if d.Kind == "interface" {
+			ifaces = append(ifaces, d)
+		}
+	}
+	sort.Slice(ifaces, func(i, j int) bool { return ifaces[i].Name < ifaces[j].Name })
+	for _, d := range gb.decls {
+		if d.Kind != "type" {
+			continue
+		}
+		for _, in := range ifaces {
+			if !supports(d.tn, in.tn.Type.(*InterfaceType)) {
+				continue
+			}
+			d.Implements = append(d.Implements, in.Name)
+		fields:
+			for _, f := range in.Fields {
+				for _, o := range d.Fields {
+					if o.Name == f.Name {
+						continue fields
+					}
+				}
+				d.Fields = append(d.Fields, f)
+			}
+		}
+	}
+}
+
+func gqlDescription(buf *bytes.Buffer, indent string, d Docs) {
+	text := description(d)
+	if text == "" {
+		return
+	}
+	if !strings.Contains(text, "\n") {
+		q, _ := json.Marshal(text)
+		fmt.Fprintf(buf, "%s%s\n", indent, q)
+		return
+	}
+	buf.WriteString(indent + `"""` + "\n")
+	for _, l := range strings.Split(strings.Replace(text, `"""`, `\"""`, -1), "\n") {
+		buf.WriteString(strings.TrimRight(indent+l, " ") + "\n")
+	}
+	buf.WriteString(indent + `"""` + "\n")
+}
+
+// source return the schema, the scalars are the first and the other declarations
+// are sorted by the name
+func (gb *gqlBuilder) source() string {
+	buf := &bytes.Buffer{}
+	var scalars []string
+	for s := range gb.scalars {
+		scalars = append(scalars, s)
+	}
+	sort.Strings(scalars)
+	for _, s := range scalars {
+		fmt.Fprintf(buf, "scalar %s\n", s)
+	}
+
+	decls := make([]*gqlDecl, 0, len(gb.decls))
+	for _, d := range gb.decls {
+		decls = append(decls, d)
+	}
+	sort.Slice(decls, func(i, j int) bool { return decls[i].Name < decls[j].Name })
+	for i, d := range decls {
+		if i > 0 || len(scalars) > 0 {
+			buf.WriteString("\n")
+		}
+		gqlDescription(buf, "", d.Docs)
+		buf.WriteString(d.Kind + " " + d.Name)
+		if len(d.Implements) > 0 {
+			buf.WriteString(" implements " + strings.Join(d.Implements, " & "))
+		}
+		buf.WriteString(" {\n")
+		for _, f := range d.Fields {
+			gqlDescription(buf, "  ", f.Docs)
+			if d.Kind == "enum" {
+				fmt.Fprintf(buf, "  %s\n", f.Name)
+				continue
+			}
+			fmt.Fprintf(buf, "  %s: %s\n", f.Name, f.Type)
+		}
+		buf.WriteString("}\n")
+	}
+	return buf.String()
+}
@@ -0,0 +1,159 @@
+package humanize
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+var gqlSrc = `package test
+
+import "time"
+
+// Node is an object with an id
+type Node interface {
+	// ID return the id
+	ID() string
+}
+
+// Role of the user
+type Role int
+
+const (
+	// RoleAdmin can do anything
+	RoleAdmin Role = iota
+	RoleGuest
+)
+
+// User is a user
+type User struct {
+	Name    string            ` + "`json:\"name\"`" + `
+	Email   *string           ` + "`json:\"email\"`" + `
+	Nick    string            ` + "`json:\"nick,omitempty\" graphql:\"nickname\"`" + `
+	Role    Role
+	Friends []*User
+	Tags    Tags
+	Born    time.Time
+	Meta    map[string]string
+	Labels  Labels
+	Avatar  Raw
+	Secret  string            ` + "`graphql:\"-\"`" + `
+}
+
+func (u *User) ID() string { return u.Name }
+
+// FullName return the full name
+func (u User) FullName() string { return "" }
+
+func (u User) Posts() ([]Post, error) { return nil, nil }
+
+func (u User) Lookup(s string) string { return s }
+
+type Post struct {
+	Title string
+}
+
+type Tags []string
+
+type Labels Tags
+
+type Raw []byte
+
+// NewUser is the input
+// @Input
+type NewUser struct {
+	Name string ` + "`json:\"name\"`" + `
+	Role *Role
+}
+
+type Bad struct {
+	Ch chan int
+}
+`
+
+func TestGraphQL(t *testing.T) {
+	Convey("GraphQL test", t, func() {
+		var p = &Package{Path: "example.com/test", Name: "test"}
+		f, err := ParseFile(gqlSrc, p)
+		So(err, ShouldBeNil)
+		p.Files = append(p.Files, f)
+		So(lateBind(p), ShouldBeNil)
+
+		src, err := GraphQL(p, GraphQLOptions{}, "Node", "NewUser")
+		So(err, ShouldBeNil)
+		So(src, ShouldEqual, `scalar JSON
+scalar Time
+
+"NewUser is the input"
+input NewUser {
+  name: String!
+  role: Role
+}
+
+"Node is an object with an id"
+interface Node {
+  "ID return the id"
+  id: String!
+}
+
+"Role of the user"
+enum Role {
+  "RoleAdmin can do anything"
+  ADMIN
+  GUEST
+}
+
+"User is a user"
+type User implements Node {
+  name: String!
+  email: String
+  nickname: String
+  role: Role!
+  friends: [User]!
+  tags: [String!]!
+  born: Time!
+  meta: JSON!
+  labels: [String!]!
+  avatar: String!
+  "ID return the id"
+  id: String!
+}
+`)
+
+		Convey("methods", func() {
+			src, err := GraphQL(p, GraphQLOptions{Methods: true}, "User")
+			So(err, ShouldBeNil)
+			So(src, ShouldContainSubstring, `type User {
+  name: String!
+  email: String
+  nickname: String
+  role: Role!
+  friends: [User]!
+  tags: [String!]!
+  born: Time!
+  meta: JSON!
+  labels: [String!]!
+  avatar: String!
+  "FullName return the full name"
+  fullName: String!
+  posts: [Post!]!
+  id: String!
+}
+`)
+			So(src, ShouldContainSubstring, "type Post {")
+		})
+
+		Convey("errors", func() {
+			_, err := GraphQL(p, GraphQLOptions{Inputs: []string{"User"}}, "User", "Node")
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldEqual, "type Node: type User is used as an input and an output type")
+
+			_, err = GraphQL(p, GraphQLOptions{})
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldEqual, "type Bad: field Ch: chan int can not be mapped")
+
+			_, err = GraphQL(p, GraphQLOptions{}, "Tags")
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
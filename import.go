@@ -14,6 +14,12 @@ type Import struct {
 	Name string
 	Path string
 	Docs Docs
+	// GroupDocs is the docs of the declaration group, like import ( ... )
+	GroupDocs Docs
+	// SpecDocs is the docs of the declaration itself, Docs is both of them
+	SpecDocs Docs
+	// Comment is the trailing comment
+	Comment Docs
 }
 
 type importWalker struct {
@@ -74,9 +80,12 @@ func peekPackageName(pkg string) (xx string) {
 // NewImport extract a new import entry
 func NewImport(i *ast.ImportSpec, c *ast.CommentGroup) *Import {
 	res := &Import{
-		Name: "",
-		Path: strings.Trim(i.Path.Value, `"`),
-		Docs: docsFromNodeDoc(c, i.Doc),
+		Name:      "",
+		Path:      strings.Trim(i.Path.Value, `"`),
+		Docs:      docsFromNodeDoc(c, i.Doc),
+		GroupDocs: docsFromNodeDoc(c),
+		SpecDocs:  docsFromNodeDoc(i.Doc),
+		Comment:   docsFromNodeDoc(i.Comment),
 	}
 	if i.Name != nil {
 		res.Name = i.Name.String()
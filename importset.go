@@ -0,0 +1,173 @@
+package humanize
+
+import (
+	"bytes"
+	"go/token"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ImportSet is the list of imports that a generated file is using
+type ImportSet struct {
+	// Package is the import path of the generated file, the types from this package
+	// are without the package name
+	Package string
+
+	names map[string]string // path => name
+	used  map[string]string // name => path
+	real  map[string]string // path => the package name, peeking the name is not cheap
+}
+
+// NewImportSet return an empty import set
+func NewImportSet() *ImportSet {
+	return &ImportSet{
+		names: make(map[string]string),
+		used:  make(map[string]string),
+		real:  make(map[string]string),
+	}
+}
+
+// isVersion check for the version suffix of the import path, like v2
+func isVersion(s string) bool {
+	if len(s) < 2 || s[0] != 'v' {
+		return false
+	}
+	for _, r := range s[1:] {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// assumedName return the package name base on the import path, like the goimports,
+// gopkg.in/yaml.v2 is yaml and github.com/x/go-foo/v2 is foo
+func assumedName(p string) string {
+	base := path.Base(p)
+	if isVersion(base) && path.Dir(p) != "." {
+		base = path.Base(path.Dir(p))
+	}
+	if i := strings.IndexByte(base, '.'); i > 0 {
+		base = base[:i]
+	}
+	base = strings.TrimPrefix(base, "go-")
+	base = strings.Map(func(r rune) rune {
+		if r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, base)
+	if !token.IsIdentifier(base) {
+		return "pkg"
+	}
+	return base
+}
+
+// packageName return the real package name if the package is available, if not the
+// name is from the import path
+func packageName(p string) string {
+	if _, err := translateToFullPath(p); err == nil {
+		if name := peekPackageName(p); token.IsIdentifier(name) {
+			return name
+		}
+	}
+	return assumedName(p)
+}
+
+func (is *ImportSet) packageName(p string) string {
+	if name, ok := is.real[p]; ok {
+		return name
+	}
+	name := packageName(p)
+	is.real[p] = name
+	return name
+}
+
+// Qualifier add the package to the set and return the name that should be used
+// for the package in the file. it is empty for the current package
+func (is *ImportSet) Qualifier(path string) string {
+	if path == is.Package {
+		return ""
+	}
+	return is.Alias(path, "")
+}
+
+// Alias add the package with the preferred name, if the name is used by another
+// package a number is added to it. empty name means the real package name. the
+// result is the name that should be used in the file
+func (is *ImportSet) Alias(path, name string) string {
+	if n, ok := is.names[path]; ok {
+		return n
+	}
+	if name == "" {
+		name = is.packageName(path)
+	}
+	if name != "_" && name != "." {
+		base := name
+		for i := 2; is.used[name] != ""; i++ {
+			name = base + strconv.Itoa(i)
+		}
+		is.used[name] = path
+	}
+	is.names[path] = name
+	return name
+}
+
+// Imports return the imports in the set, sorted by the path. the name is empty if
+// it is not required
+func (is *ImportSet) Imports() []*Import {
+	res := make([]*Import, 0, len(is.names))
+	for p, name := range is.names {
+		// the name is there if it is not clear from the path
+		if name == assumedName(p) && name == is.packageName(p) {
+			name = ""
+		}
+		res = append(res, &Import{Name: name, Path: p})
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Path < res[j].Path })
+	return res
+}
+
+// isStd check if the import path is from the standard library, the same as goimports
+// it is the packages without a dot in the first part of the path
+func isStd(p string) bool {
+	first := strings.SplitN(p, "/", 2)[0]
+	return !strings.Contains(first, ".")
+}
+
+// Source return the import declaration, the standard library packages are the first
+// group and the other packages are the second one. it is empty if the set is empty
+func (is *ImportSet) Source() string {
+	imports := is.Imports()
+	if len(imports) == 0 {
+		return ""
+	}
+	var std, other []string
+	for _, imp := range imports {
+		line := "\t" + strconv.Quote(imp.Path)
+		if imp.Name != "" {
+			line = "\t" + imp.Name + " " + strconv.Quote(imp.Path)
+		}
+		if isStd(imp.Path) {
+			std = append(std, line)
+		} else {
+			other = append(other, line)
+		}
+	}
+	buf := &bytes.Buffer{}
+	buf.WriteString("import (\n")
+	for _, l := range std {
+		buf.WriteString(l + "\n")
+	}
+	if len(std) > 0 && len(other) > 0 {
+		buf.WriteString("\n")
+	}
+	for _, l := range other {
+		buf.WriteString(l + "\n")
+	}
+	buf.WriteString(")\n")
+	return buf.String()
+}
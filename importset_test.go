@@ -0,0 +1,72 @@
+package humanize
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestImportSet(t *testing.T) {
+	Convey("Import set test", t, func() {
+		Convey("names from the path", func() {
+			So(assumedName("gopkg.in/yaml.v2"), ShouldEqual, "yaml")
+			So(assumedName("github.com/x/go-foo/v2"), ShouldEqual, "foo")
+			So(assumedName("example.com/my-pkg"), ShouldEqual, "mypkg")
+			So(assumedName("example.com/type"), ShouldEqual, "pkg")
+			So(isStd("net/http"), ShouldBeTrue)
+			So(isStd("example.com/x"), ShouldBeFalse)
+		})
+
+		Convey("aliases", func() {
+			is := NewImportSet()
+			is.Package = "example.com/current"
+			So(is.Qualifier("example.com/current"), ShouldEqual, "")
+			So(is.Qualifier("math/rand"), ShouldEqual, "rand")
+			So(is.Qualifier("crypto/rand"), ShouldEqual, "rand2")
+			So(is.Qualifier("math/rand"), ShouldEqual, "rand")
+			So(is.Qualifier("gopkg.in/yaml.v2"), ShouldEqual, "yaml")
+			So(is.Alias("example.com/other/yaml", "yaml"), ShouldEqual, "yaml2")
+			So(is.Alias("example.com/driver", "_"), ShouldEqual, "_")
+			So(is.Alias("fmt", "f"), ShouldEqual, "f")
+
+			So(is.Source(), ShouldEqual, `import (
+	rand2 "crypto/rand"
+	f "fmt"
+	"math/rand"
+
+	_ "example.com/driver"
+	yaml2 "example.com/other/yaml"
+	"gopkg.in/yaml.v2"
+)
+`)
+			So(NewImportSet().Source(), ShouldBeEmpty)
+		})
+
+		Convey("render the types", func() {
+			var p = &Package{Path: "example.com/test", Name: "test"}
+			f, err := ParseFile(`package test
+import (
+	r "math/rand"
+	"github.com/goraz/humanize/fixture"
+)
+type T struct {
+	R *r.Rand
+	F fixture.T1
+	S Local
+}
+type Local int
+`, p)
+			So(err, ShouldBeNil)
+			p.Files = append(p.Files, f)
+			tn, err := p.FindType("T")
+			So(err, ShouldBeNil)
+
+			is := NewImportSet()
+			is.Alias("example.com/rand", "")
+			src, err := NewPrinter(is).Sprint(tn.Type)
+			So(err, ShouldBeNil)
+			So(src, ShouldEqual, "struct {\n\tR *rand2.Rand\n\tF fixture.T1\n\tS Local\n}")
+			So(is.Source(), ShouldEqual, "import (\n\trand2 \"math/rand\"\n\n\t\"example.com/rand\"\n\t\"github.com/goraz/humanize/fixture\"\n)\n")
+		})
+	})
+}
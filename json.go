@@ -0,0 +1,922 @@
+package humanize
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+)
+
+// SchemaVersion is the version of the JSON document of the package, it changes on
+// every incompatible change in the document
+const SchemaVersion = "humanize/v1"
+
+// the kind of the types in the JSON document
+const (
+	kindIdent     = "ident"
+	kindSelector  = "selector"
+	kindStar      = "star"
+	kindArray     = "array"
+	kindSlice     = "slice"
+	kindEllipsis  = "ellipsis"
+	kindMap       = "map"
+	kindChan      = "chan"
+	kindFunc      = "func"
+	kindStruct    = "struct"
+	kindInterface = "interface"
+)
+
+type jsonPosition struct {
+	File   string `json:"file,omitempty"`
+	Offset int    `json:"offset"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+}
+
+type jsonAnnotation struct {
+	Name   string            `json:"name"`
+	Args   []string          `json:"args,omitempty"`
+	Params map[string]string `json:"params,omitempty"`
+	Pos    *jsonPosition     `json:"pos,omitempty"`
+}
+
+type jsonDirective struct {
+	Name string        `json:"name"`
+	Text string        `json:"text,omitempty"`
+	Args []string      `json:"args,omitempty"`
+	Pos  *jsonPosition `json:"pos,omitempty"`
+}
+
+type jsonEmbedFiles struct {
+	Kind     string   `json:"kind"`
+	Patterns []string `json:"patterns"`
+	Files    []string `json:"files,omitempty"`
+}
+
+// jsonType is a single type, the kind is the discriminator and the other fields are
+// base on the kind
+type jsonType struct {
+	Kind string `json:"kind"`
+	// Name is the name of ident and selector types
+	Name string `json:"name,omitempty"`
+	// Package is the import path of selector types, and Alias is the package name
+	// in the source
+	Package string `json:"package,omitempty"`
+	Alias   string `json:"alias,omitempty"`
+	Len     int    `json:"len,omitempty"`
+	// Elem is the type of star, array, slice, ellipsis and chan types
+	Elem  *jsonType `json:"elem,omitempty"`
+	Key   *jsonType `json:"key,omitempty"`
+	Value *jsonType `json:"value,omitempty"`
+	// Dir is the direction of the chan type, send, recv or empty for both
+	Dir          string          `json:"dir,omitempty"`
+	Params       []*jsonVariable `json:"params,omitempty"`
+	Results      []*jsonVariable `json:"results,omitempty"`
+	Variadic     bool            `json:"variadic,omitempty"`
+	ParamGroups  []int           `json:"paramGroups,omitempty"`
+	ResultGroups []int           `json:"resultGroups,omitempty"`
+	Fields       []*jsonVariable `json:"fields,omitempty"`
+	Embeds       []*jsonEmbed    `json:"embeds,omitempty"`
+	// Methods and Embedded are for the interface types
+	Methods  []*jsonFunction `json:"methods,omitempty"`
+	Embedded []*jsonType     `json:"embedded,omitempty"`
+}
+
+type jsonEmbed struct {
+	Type    *jsonType `json:"type"`
+	Docs    Docs      `json:"docs,omitempty"`
+	Comment Docs      `json:"comment,omitempty"`
+	Tags    string    `json:"tags,omitempty"`
+}
+
+// jsonVariable is for variables, parameters, struct fields and constants
+type jsonVariable struct {
+	Name string    `json:"name,omitempty"`
+	Type *jsonType `json:"type,omitempty"`
+	// Explicit is true if the type of a package level variable or constant is in the
+	// source, not inferred from the value
+	Explicit    bool              `json:"explicit,omitempty"`
+	Docs        Docs              `json:"docs,omitempty"`
+	GroupDocs   Docs              `json:"groupDocs,omitempty"`
+	SpecDocs    Docs              `json:"specDocs,omitempty"`
+	Comment     Docs              `json:"comment,omitempty"`
+	Annotations []*jsonAnnotation `json:"annotations,omitempty"`
+	Directives  []*jsonDirective  `json:"directives,omitempty"`
+	EmbedFiles  *jsonEmbedFiles   `json:"embedFiles,omitempty"`
+	Initializer string            `json:"initializer,omitempty"`
+	Tags        string            `json:"tags,omitempty"`
+	// Group is the declaration that the variable or constant is in, starting from 1
+	Group int `json:"group,omitempty"`
+	// Index is the index in the multi value initializer, like b in var a, b = f()
+	Index int `json:"index,omitempty"`
+	// Value and Iota are for constants
+	Value string `json:"value,omitempty"`
+	Iota  int    `json:"iota,omitempty"`
+}
+
+type jsonFunction struct {
+	Name        string            `json:"name"`
+	Receiver    *jsonVariable     `json:"receiver,omitempty"`
+	Docs        Docs              `json:"docs,omitempty"`
+	Type        *jsonType         `json:"type"`
+	Annotations []*jsonAnnotation `json:"annotations,omitempty"`
+	Directives  []*jsonDirective  `json:"directives,omitempty"`
+	Types       []*jsonTypeName   `json:"types,omitempty"`
+}
+
+// jsonTypeName is a type declaration, the methods are the name of the functions in
+// the package, not the entire function
+type jsonTypeName struct {
+	Name        string            `json:"name"`
+	Type        *jsonType         `json:"type"`
+	Docs        Docs              `json:"docs,omitempty"`
+	GroupDocs   Docs              `json:"groupDocs,omitempty"`
+	SpecDocs    Docs              `json:"specDocs,omitempty"`
+	Comment     Docs              `json:"comment,omitempty"`
+	Annotations []*jsonAnnotation `json:"annotations,omitempty"`
+	Directives  []*jsonDirective  `json:"directives,omitempty"`
+	Methods     []string          `json:"methods,omitempty"`
+	StarMethods []string          `json:"starMethods,omitempty"`
+}
+
+type jsonImport struct {
+	Name      string `json:"name,omitempty"`
+	Path      string `json:"path"`
+	Docs      Docs   `json:"docs,omitempty"`
+	GroupDocs Docs   `json:"groupDocs,omitempty"`
+	SpecDocs  Docs   `json:"specDocs,omitempty"`
+	Comment   Docs   `json:"comment,omitempty"`
+}
+
+type jsonLooseComment struct {
+	Docs Docs          `json:"docs"`
+	Pos  *jsonPosition `json:"pos,omitempty"`
+}
+
+type jsonFile struct {
+	Name          string              `json:"name,omitempty"`
+	Package       string              `json:"package"`
+	Docs          Docs                `json:"docs,omitempty"`
+	Annotations   []*jsonAnnotation   `json:"annotations,omitempty"`
+	Imports       []*jsonImport       `json:"imports,omitempty"`
+	Constants     []*jsonVariable     `json:"constants,omitempty"`
+	Variables     []*jsonVariable     `json:"variables,omitempty"`
+	Types         []*jsonTypeName     `json:"types,omitempty"`
+	Functions     []*jsonFunction     `json:"functions,omitempty"`
+	LooseComments []*jsonLooseComment `json:"looseComments,omitempty"`
+	Directives    []*jsonDirective    `json:"directives,omitempty"`
+}
+
+type jsonDiagnostic struct {
+	Pos     *jsonPosition `json:"pos,omitempty"`
+	Message string        `json:"message"`
+}
+
+type jsonPackage struct {
+	Schema      string            `json:"schema"`
+	Path        string            `json:"path"`
+	Name        string            `json:"name"`
+	Dir         string            `json:"dir,omitempty"`
+	Files       []*jsonFile       `json:"files"`
+	Diagnostics []*jsonDiagnostic `json:"diagnostics,omitempty"`
+}
+
+var embedKinds = map[EmbedKind]string{
+	EmbedString: "string",
+	EmbedBytes:  "bytes",
+	EmbedFS:     "fs",
+}
+
+// MarshalJSON return the package as a versioned JSON document. each type has a kind,
+// the selector types have the import path and the methods of the types are the
+// name of the functions. the function bodies are not in the document
+func (p *Package) MarshalJSON() ([]byte, error) {
+	jp := &jsonPackage{
+		Schema: SchemaVersion,
+		Path:   p.Path,
+		Name:   p.Name,
+		Dir:    p.Dir,
+		Files:  []*jsonFile{},
+	}
+	for _, d := range p.Diagnostics {
+		jp.Diagnostics = append(jp.Diagnostics, &jsonDiagnostic{Pos: toJSONPosition(d.Pos), Message: d.Message})
+	}
+	for _, f := range p.Files {
+		jf, err := toJSONFile(f)
+		if err != nil {
+			return nil, err
+		}
+		jp.Files = append(jp.Files, jf)
+	}
+	return json.Marshal(jp)
+}
+
+// UnmarshalJSON load the package from the JSON document, the schema version must be
+// the same as the SchemaVersion
+func (p *Package) UnmarshalJSON(data []byte) error {
+	jp := &jsonPackage{}
+	if err := json.Unmarshal(data, jp); err != nil {
+		return err
+	}
+	if jp.Schema != SchemaVersion {
+		return fmt.Errorf("unsupported schema %q, the supported one is %q", jp.Schema, SchemaVersion)
+	}
+	*p = Package{Path: jp.Path, Name: jp.Name, Dir: jp.Dir}
+	for _, d := range jp.Diagnostics {
+		p.Diagnostics = append(p.Diagnostics, Diagnostic{Pos: d.Pos.position(), Message: d.Message})
+	}
+	for _, jf := range jp.Files {
+		f, err := fromJSONFile(jf, p)
+		if err != nil {
+			return err
+		}
+		p.Files = append(p.Files, f)
+	}
+	// the methods are the references to the functions
+	for i, f := range p.Files {
+		for j, tn := range f.Types {
+			jt := jp.Files[i].Types[j]
+			var err error
+			if tn.Methods, err = p.functions(jt.Methods); err != nil {
+				return err
+			}
+			if tn.StarMethods, err = p.functions(jt.StarMethods); err != nil {
+				return err
+			}
+		}
+	}
+	p.resolved = true
+	return nil
+}
+
+func (p *Package) functions(names []string) ([]*Function, error) {
+	var res []*Function
+	for _, name := range names {
+		fn, err := p.FindFunction(name)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, fn)
+	}
+	return res, nil
+}
+
+func toJSONPosition(p token.Position) *jsonPosition {
+	if !p.IsValid() {
+		return nil
+	}
+	return &jsonPosition{File: p.Filename, Offset: p.Offset, Line: p.Line, Column: p.Column}
+}
+
+func (jp *jsonPosition) position() token.Position {
+	if jp == nil {
+		return token.Position{}
+	}
+	return token.Position{Filename: jp.File, Offset: jp.Offset, Line: jp.Line, Column: jp.Column}
+}
+
+func toJSONAnnotations(a Annotations) []*jsonAnnotation {
+	var res []*jsonAnnotation
+	for _, an := range a {
+		res = append(res, &jsonAnnotation{Name: an.Name, Args: an.Args, Params: an.Params, Pos: toJSONPosition(an.Pos)})
+	}
+	return res
+}
+
+func fromJSONAnnotations(ja []*jsonAnnotation) Annotations {
+	var res Annotations
+	for _, an := range ja {
+		res = append(res, &Annotation{Name: an.Name, Args: an.Args, Params: an.Params, Pos: an.Pos.position()})
+	}
+	return res
+}
+
+func toJSONDirectives(d []*Directive) []*jsonDirective {
+	var res []*jsonDirective
+	for _, dr := range d {
+		res = append(res, &jsonDirective{Name: dr.Name, Text: dr.Text, Args: dr.Args, Pos: toJSONPosition(dr.Pos)})
+	}
+	return res
+}
+
+func fromJSONDirectives(jd []*jsonDirective) []*Directive {
+	var res []*Directive
+	for _, dr := range jd {
+		// parse it again for the build constraint
+		d := parseDirective(dr.Name + " " + dr.Text)
+		d.Args = dr.Args
+		d.Pos = dr.Pos.position()
+		res = append(res, d)
+	}
+	return res
+}
+
+func toJSONType(t Type) (*jsonType, error) {
+	var err error
+	switch x := t.(type) {
+	case nil:
+		return nil, nil
+	case *IdentType:
+		return &jsonType{Kind: kindIdent, Name: x.Ident}, nil
+	case *SelectorType:
+		res := &jsonType{Kind: kindSelector, Name: x.Type.GetDefinition()}
+		if x.pkg != nil {
+			res.Package, res.Alias = x.pkg.Path, x.pkg.Name
+		}
+		return res, nil
+	case *StarType:
+		res := &jsonType{Kind: kindStar}
+		res.Elem, err = toJSONType(x.Target)
+		return res, err
+	case *EllipsisType:
+		res := &jsonType{Kind: kindEllipsis}
+		res.Elem, err = toJSONType(x.Type)
+		return res, err
+	case *ArrayType:
+		res := &jsonType{Kind: kindArray, Len: x.Len}
+		if x.Slice {
+			res = &jsonType{Kind: kindSlice}
+		}
+		res.Elem, err = toJSONType(x.Type)
+		return res, err
+	case *MapType:
+		res := &jsonType{Kind: kindMap}
+		if res.Key, err = toJSONType(x.Key); err != nil {
+			return nil, err
+		}
+		res.Value, err = toJSONType(x.Value)
+		return res, err
+	case *ChannelType:
+		res := &jsonType{Kind: kindChan}
+		switch x.Direction {
+		case ast.SEND:
+			res.Dir = "send"
+		case ast.RECV:
+			res.Dir = "recv"
+		}
+		res.Elem, err = toJSONType(x.Type)
+		return res, err
+	case *FuncType:
+		res := &jsonType{
+			Kind:         kindFunc,
+			Variadic:     x.Variadic,
+			ParamGroups:  x.ParameterGroups,
+			ResultGroups: x.ResultGroups,
+		}
+		if res.Params, err = toJSONVariables(x.Parameters); err != nil {
+			return nil, err
+		}
+		res.Results, err = toJSONVariables(x.Results)
+		return res, err
+	case *StructType:
+		res := &jsonType{Kind: kindStruct}
+		for _, e := range x.Embeds {
+			et, err := toJSONType(e.Type)
+			if err != nil {
+				return nil, err
+			}
+			res.Embeds = append(res.Embeds, &jsonEmbed{Type: et, Docs: e.Docs, Comment: e.Comment, Tags: string(e.Tags)})
+		}
+		for _, fl := range x.Fields {
+			jv, err := toJSONVariable(&fl.Variable)
+			if err != nil {
+				return nil, err
+			}
+			jv.Tags = string(fl.Tags)
+			res.Fields = append(res.Fields, jv)
+		}
+		return res, nil
+	case *InterfaceType:
+		res := &jsonType{Kind: kindInterface}
+		for _, e := range x.Embed {
+			et, err := toJSONType(e)
+			if err != nil {
+				return nil, err
+			}
+			res.Embedded = append(res.Embedded, et)
+		}
+		for _, fn := range x.Functions {
+			jf, err := toJSONFunction(fn)
+			if err != nil {
+				return nil, err
+			}
+			res.Methods = append(res.Methods, jf)
+		}
+		return res, nil
+	}
+	return nil, fmt.Errorf("unsupported type %T", t)
+}
+
+// jsonDecoder create the model from the document, the types are in the package and
+// the selectors use the imports of the file
+type jsonDecoder struct {
+	f *File
+	p *Package
+}
+
+func (jd *jsonDecoder) base() srcBase {
+	return srcBase{pkg: jd.p}
+}
+
+func (jd *jsonDecoder) typ(jt *jsonType) (Type, error) {
+	if jt == nil {
+		return nil, nil
+	}
+	var err error
+	switch jt.Kind {
+	case kindIdent:
+		return &IdentType{srcBase: jd.base(), Ident: jt.Name}, nil
+	case kindSelector:
+		var imp *Import
+		for _, i := range jd.f.Imports {
+			if i.Path == jt.Package && i.Name == jt.Alias {
+				imp = i
+			}
+		}
+		if imp == nil {
+			imp = &Import{Name: jt.Alias, Path: jt.Package}
+		}
+		return &SelectorType{
+			srcBase: jd.base(),
+			pkg:     imp,
+			Type:    &IdentType{srcBase: jd.base(), Ident: jt.Name},
+		}, nil
+	case kindStar:
+		res := &StarType{srcBase: jd.base()}
+		res.Target, err = jd.typ(jt.Elem)
+		return res, err
+	case kindEllipsis:
+		res := &ArrayType{srcBase: jd.base()}
+		res.Type, err = jd.typ(jt.Elem)
+		return &EllipsisType{ArrayType: res}, err
+	case kindArray, kindSlice:
+		res := &ArrayType{srcBase: jd.base(), Slice: jt.Kind == kindSlice, Len: jt.Len}
+		res.Type, err = jd.typ(jt.Elem)
+		return res, err
+	case kindMap:
+		res := &MapType{srcBase: jd.base()}
+		if res.Key, err = jd.typ(jt.Key); err != nil {
+			return nil, err
+		}
+		res.Value, err = jd.typ(jt.Value)
+		return res, err
+	case kindChan:
+		res := &ChannelType{srcBase: jd.base(), Direction: ast.SEND | ast.RECV}
+		switch jt.Dir {
+		case "send":
+			res.Direction = ast.SEND
+		case "recv":
+			res.Direction = ast.RECV
+		}
+		res.Type, err = jd.typ(jt.Elem)
+		return res, err
+	case kindFunc:
+		res := &FuncType{
+			srcBase:         jd.base(),
+			Variadic:        jt.Variadic,
+			ParameterGroups: jt.ParamGroups,
+			ResultGroups:    jt.ResultGroups,
+		}
+		if res.Parameters, err = jd.variables(jt.Params); err != nil {
+			return nil, err
+		}
+		res.Results, err = jd.variables(jt.Results)
+		return res, err
+	case kindStruct:
+		res := &StructType{srcBase: jd.base()}
+		for _, e := range jt.Embeds {
+			et, err := jd.typ(e.Type)
+			if err != nil {
+				return nil, err
+			}
+			res.Embeds = append(res.Embeds, &Embed{Type: et, Docs: e.Docs, Comment: e.Comment, Tags: reflect.StructTag(e.Tags)})
+		}
+		for _, jf := range jt.Fields {
+			v, err := jd.variable(jf)
+			if err != nil {
+				return nil, err
+			}
+			res.Fields = append(res.Fields, &Field{Variable: *v, Tags: reflect.StructTag(jf.Tags)})
+		}
+		return res, nil
+	case kindInterface:
+		res := &InterfaceType{srcBase: jd.base()}
+		for _, e := range jt.Embedded {
+			et, err := jd.typ(e)
+			if err != nil {
+				return nil, err
+			}
+			res.Embed = append(res.Embed, et)
+		}
+		for _, m := range jt.Methods {
+			fn, err := jd.function(m)
+			if err != nil {
+				return nil, err
+			}
+			res.Functions = append(res.Functions, fn)
+		}
+		return res, nil
+	}
+	return nil, fmt.Errorf("unknown type kind %q", jt.Kind)
+}
+
+func toJSONVariable(v *Variable) (*jsonVariable, error) {
+	t, err := toJSONType(v.Type)
+	if err != nil {
+		return nil, err
+	}
+	res := &jsonVariable{
+		Name:        v.Name,
+		Type:        t,
+		Docs:        v.Docs,
+		GroupDocs:   v.GroupDocs,
+		SpecDocs:    v.SpecDocs,
+		Comment:     v.Comment,
+		Annotations: toJSONAnnotations(v.Annotations),
+		Directives:  toJSONDirectives(v.Directives),
+		Initializer: v.Initializer,
+		Index:       v.indx,
+	}
+	if v.EmbedFiles != nil {
+		res.EmbedFiles = &jsonEmbedFiles{
+			Kind:     embedKinds[v.EmbedFiles.Kind],
+			Patterns: v.EmbedFiles.Patterns,
+			Files:    v.EmbedFiles.Files,
+		}
+	}
+	return res, nil
+}
+
+func toJSONVariables(vars []*Variable) ([]*jsonVariable, error) {
+	var res []*jsonVariable
+	for _, v := range vars {
+		jv, err := toJSONVariable(v)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, jv)
+	}
+	return res, nil
+}
+
+// variable create the variable, the type expression is there for the parameters
+// and the fields, since they always have a type
+func (jd *jsonDecoder) variable(jv *jsonVariable) (*Variable, error) {
+	t, err := jd.typ(jv.Type)
+	if err != nil {
+		return nil, err
+	}
+	res := &Variable{
+		Name:        jv.Name,
+		Type:        t,
+		Docs:        jv.Docs,
+		GroupDocs:   jv.GroupDocs,
+		SpecDocs:    jv.SpecDocs,
+		Comment:     jv.Comment,
+		Annotations: fromJSONAnnotations(jv.Annotations),
+		Directives:  fromJSONDirectives(jv.Directives),
+		Initializer: jv.Initializer,
+		indx:        jv.Index,
+		file:        jd.f,
+	}
+	if t != nil {
+		res.typeExpr = ToAstExpr(t, nil)
+	}
+	if jv.EmbedFiles != nil {
+		res.EmbedFiles = &EmbedFiles{Patterns: jv.EmbedFiles.Patterns, Files: jv.EmbedFiles.Files}
+		for k, name := range embedKinds {
+			if name == jv.EmbedFiles.Kind {
+				res.EmbedFiles.Kind = k
+			}
+		}
+	}
+	return res, nil
+}
+
+func (jd *jsonDecoder) variables(jvs []*jsonVariable) ([]*Variable, error) {
+	var res []*Variable
+	for _, jv := range jvs {
+		v, err := jd.variable(jv)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, v)
+	}
+	return res, nil
+}
+
+// initializer parse the initializer again, for the expression
+func (jd *jsonDecoder) initializer(src string) (ast.Expr, *Expression) {
+	if src == "" {
+		return nil, nil
+	}
+	e, err := parser.ParseExpr(src)
+	if err != nil {
+		return nil, nil
+	}
+	return e, newExpression(e, src, jd.f, jd.p)
+}
+
+func toJSONFunction(fn *Function) (*jsonFunction, error) {
+	t, err := toJSONType(fn.Type)
+	if err != nil {
+		return nil, err
+	}
+	res := &jsonFunction{
+		Name:        fn.Name,
+		Docs:        fn.Docs,
+		Type:        t,
+		Annotations: toJSONAnnotations(fn.Annotations),
+		Directives:  toJSONDirectives(fn.Directives),
+	}
+	if fn.Receiver != nil {
+		if res.Receiver, err = toJSONVariable(fn.Receiver); err != nil {
+			return nil, err
+		}
+	}
+	for _, tn := range fn.Types {
+		jt, err := toJSONTypeName(tn)
+		if err != nil {
+			return nil, err
+		}
+		res.Types = append(res.Types, jt)
+	}
+	return res, nil
+}
+
+func (jd *jsonDecoder) function(jf *jsonFunction) (*Function, error) {
+	t, err := jd.typ(jf.Type)
+	if err != nil {
+		return nil, err
+	}
+	ft, ok := t.(*FuncType)
+	if !ok {
+		return nil, fmt.Errorf("the type of function %s is not a func", jf.Name)
+	}
+	res := &Function{
+		Name:        jf.Name,
+		Docs:        jf.Docs,
+		Type:        ft,
+		Annotations: fromJSONAnnotations(jf.Annotations),
+		Directives:  fromJSONDirectives(jf.Directives),
+		file:        jd.f,
+		pkg:         jd.p,
+	}
+	if jf.Receiver != nil {
+		if res.Receiver, err = jd.variable(jf.Receiver); err != nil {
+			return nil, err
+		}
+	}
+	for _, jt := range jf.Types {
+		tn, err := jd.typeName(jt)
+		if err != nil {
+			return nil, err
+		}
+		tn.Scope = res
+		res.Types = append(res.Types, tn)
+	}
+	return res, nil
+}
+
+func toJSONTypeName(tn *TypeName) (*jsonTypeName, error) {
+	t, err := toJSONType(tn.Type)
+	if err != nil {
+		return nil, err
+	}
+	res := &jsonTypeName{
+		Name:        tn.Name,
+		Type:        t,
+		Docs:        tn.Docs,
+		GroupDocs:   tn.GroupDocs,
+		SpecDocs:    tn.SpecDocs,
+		Comment:     tn.Comment,
+		Annotations: toJSONAnnotations(tn.Annotations),
+		Directives:  toJSONDirectives(tn.Directives),
+	}
+	for _, fn := range tn.Methods {
+		res.Methods = append(res.Methods, fn.Name)
+	}
+	for _, fn := range tn.StarMethods {
+		res.StarMethods = append(res.StarMethods, fn.Name)
+	}
+	return res, nil
+}
+
+func (jd *jsonDecoder) typeName(jt *jsonTypeName) (*TypeName, error) {
+	t, err := jd.typ(jt.Type)
+	if err != nil {
+		return nil, err
+	}
+	return &TypeName{
+		Name:        jt.Name,
+		Type:        t,
+		Docs:        jt.Docs,
+		GroupDocs:   jt.GroupDocs,
+		SpecDocs:    jt.SpecDocs,
+		Comment:     jt.Comment,
+		Annotations: fromJSONAnnotations(jt.Annotations),
+		Directives:  fromJSONDirectives(jt.Directives),
+	}, nil
+}
+
+// groups return the index of the declaration of each item, starting from 1
+type groups map[*ast.GenDecl]int
+
+func (g groups) index(d *ast.GenDecl) int {
+	if d == nil {
+		return 0
+	}
+	if _, ok := g[d]; !ok {
+		g[d] = len(g) + 1
+	}
+	return g[d]
+}
+
+func toJSONFile(f *File) (*jsonFile, error) {
+	res := &jsonFile{
+		Name:        f.FileName,
+		Package:     f.PackageName,
+		Docs:        f.Docs,
+		Annotations: toJSONAnnotations(f.Annotations),
+		Directives:  toJSONDirectives(f.Directives),
+	}
+	for _, i := range f.Imports {
+		res.Imports = append(res.Imports, &jsonImport{
+			Name:      i.Name,
+			Path:      i.Path,
+			Docs:      i.Docs,
+			GroupDocs: i.GroupDocs,
+			SpecDocs:  i.SpecDocs,
+			Comment:   i.Comment,
+		})
+	}
+	g := groups{}
+	for _, c := range f.Constants {
+		t, err := toJSONType(c.Type)
+		if err != nil {
+			return nil, err
+		}
+		res.Constants = append(res.Constants, &jsonVariable{
+			Name:        c.Name,
+			Type:        t,
+			Explicit:    c.typeExpr != nil,
+			Docs:        c.Docs,
+			GroupDocs:   c.GroupDocs,
+			SpecDocs:    c.SpecDocs,
+			Comment:     c.Comment,
+			Annotations: toJSONAnnotations(c.Annotations),
+			Initializer: c.Initializer,
+			Group:       g.index(c.decl),
+			Value:       c.Value,
+			Iota:        c.iota,
+		})
+	}
+	for _, v := range f.Variables {
+		jv, err := toJSONVariable(v)
+		if err != nil {
+			return nil, err
+		}
+		jv.Explicit = v.typeExpr != nil
+		jv.Group = g.index(v.decl)
+		res.Variables = append(res.Variables, jv)
+	}
+	for _, tn := range f.Types {
+		jt, err := toJSONTypeName(tn)
+		if err != nil {
+			return nil, err
+		}
+		res.Types = append(res.Types, jt)
+	}
+	for _, fn := range f.Functions {
+		jf, err := toJSONFunction(fn)
+		if err != nil {
+			return nil, err
+		}
+		res.Functions = append(res.Functions, jf)
+	}
+	for _, lc := range f.LooseComments {
+		res.LooseComments = append(res.LooseComments, &jsonLooseComment{Docs: lc.Docs, Pos: toJSONPosition(lc.Pos)})
+	}
+	return res, nil
+}
+
+func fromJSONFile(jf *jsonFile, p *Package) (*File, error) {
+	f := &File{
+		FileName:    jf.Name,
+		PackageName: jf.Package,
+		Docs:        jf.Docs,
+		Annotations: fromJSONAnnotations(jf.Annotations),
+		Directives:  fromJSONDirectives(jf.Directives),
+		pkg:         p,
+	}
+	jd := &jsonDecoder{f: f, p: p}
+	for _, i := range jf.Imports {
+		f.Imports = append(f.Imports, &Import{
+			Name:      i.Name,
+			Path:      i.Path,
+			Docs:      i.Docs,
+			GroupDocs: i.GroupDocs,
+			SpecDocs:  i.SpecDocs,
+			Comment:   i.Comment,
+		})
+	}
+
+	decls := make(map[int]*ast.GenDecl)
+	decl := func(group int) *ast.GenDecl {
+		if group == 0 {
+			return nil
+		}
+		if _, ok := decls[group]; !ok {
+			decls[group] = &ast.GenDecl{}
+		}
+		return decls[group]
+	}
+
+	var last []*Constant // the last spec with value, for the implicit repetition
+	for _, jc := range jf.Constants {
+		t, err := jd.typ(jc.Type)
+		if err != nil {
+			return nil, err
+		}
+		c := &Constant{
+			Name:        jc.Name,
+			Type:        t,
+			Docs:        jc.Docs,
+			GroupDocs:   jc.GroupDocs,
+			SpecDocs:    jc.SpecDocs,
+			Comment:     jc.Comment,
+			Annotations: fromJSONAnnotations(jc.Annotations),
+			Value:       jc.Value,
+			Initializer: jc.Initializer,
+			iota:        jc.Iota,
+			decl:        decl(jc.Group),
+			file:        f,
+			pkg:         p,
+		}
+		if jc.Explicit && t != nil {
+			c.typeExpr = ToAstExpr(t, nil)
+		}
+		c.expr, c.Expression = jd.initializer(c.Initializer)
+		if c.Initializer != "" {
+			if len(last) > 0 && (last[0].decl != c.decl || last[0].iota != c.iota) {
+				last = nil
+			}
+			last = append(last, c)
+		} else {
+			// the same position in the last spec with value
+			n := 0
+			for _, other := range f.Constants {
+				if other.decl == c.decl && other.iota == c.iota {
+					n++
+				}
+			}
+			if len(last) > n && last[0].decl == c.decl {
+				c.expr = last[n].expr
+			}
+		}
+		f.Constants = append(f.Constants, c)
+	}
+
+	for _, jv := range jf.Variables {
+		v, err := jd.variable(jv)
+		if err != nil {
+			return nil, err
+		}
+		if !jv.Explicit {
+			v.typeExpr = nil
+		}
+		v.decl = decl(jv.Group)
+		if v.indx > 0 && len(f.Variables) > 0 {
+			// the multi value initializer is shared
+			prev := f.Variables[len(f.Variables)-1]
+			v.expr, v.Expression = prev.expr, prev.Expression
+		} else {
+			v.expr, v.Expression = jd.initializer(v.Initializer)
+			if v.Expression != nil {
+				v.Expression.nameLiterals(v.Name)
+			}
+		}
+		f.Variables = append(f.Variables, v)
+	}
+
+	for _, jt := range jf.Types {
+		tn, err := jd.typeName(jt)
+		if err != nil {
+			return nil, err
+		}
+		f.Types = append(f.Types, tn)
+	}
+	for _, jfn := range jf.Functions {
+		fn, err := jd.function(jfn)
+		if err != nil {
+			return nil, err
+		}
+		f.Functions = append(f.Functions, fn)
+	}
+	for _, lc := range jf.LooseComments {
+		f.LooseComments = append(f.LooseComments, &LooseComment{Docs: lc.Docs, Pos: lc.Pos.position()})
+	}
+	return f, nil
+}
@@ -0,0 +1,179 @@
+package humanize
+
+import (
+	"encoding/json"
+	"go/constant"
+	"go/token"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+var jsonSrc = `//go:build linux
+
+// Package test is for the json
+// @Package name=test
+package test
+
+import (
+	"io"
+	h "net/http"
+)
+
+// the colors
+const (
+	Red Color = iota + 1 // the first one
+	Green
+	Blue
+
+	a, b = iota, iota * 2
+	c, d
+)
+
+var (
+	// w is a writer
+	w      io.Writer
+	x, y   = pair()
+	ch     = make(chan<- int)
+	handle = func(h.ResponseWriter, *h.Request) {}
+)
+
+// Color is a color
+// @Enum
+type Color int
+
+// Shape is the interface
+type Shape interface {
+	io.Reader
+	// Area return the area
+	Area() float64
+}
+
+type Box struct {
+	Shape ` + "`json:\"-\"`" + `
+	// Name of the box
+	Name  string ` + "`json:\"name\"`" + ` // the name
+	Sizes [3]int
+	Items map[string][]*Box
+	Arr   *[...]int
+	H     h.Handler
+}
+
+func pair() (int, string) {
+	return 1, "a"
+}
+
+// String return the name
+//go:noinline
+func (c Color) String() string {
+	type local struct{}
+	return ""
+}
+
+func (b *Box) Join(sep string, parts ...string) (res string, err error) {
+	return "", nil
+}
+`
+
+func TestJSON(t *testing.T) {
+	Convey("JSON test", t, func() {
+		var p = &Package{Path: "example.com/test", Name: "test", Diagnostics: []Diagnostic{{Message: "a problem"}}}
+		f, err := parseFile("test.go", jsonSrc, p)
+		So(err, ShouldBeNil)
+		p.Files = append(p.Files, f)
+		So(lateBind(p), ShouldBeNil)
+
+		data, err := json.Marshal(p)
+		So(err, ShouldBeNil)
+
+		Convey("the document", func() {
+			var doc map[string]interface{}
+			So(json.Unmarshal(data, &doc), ShouldBeNil)
+			So(doc["schema"], ShouldEqual, SchemaVersion)
+			file := doc["files"].([]interface{})[0].(map[string]interface{})
+			types := file["types"].([]interface{})
+			color := types[0].(map[string]interface{})
+			So(color["type"], ShouldResemble, map[string]interface{}{"kind": "ident", "name": "int"})
+			So(color["methods"], ShouldResemble, []interface{}{"Color.String"})
+
+			box := types[2].(map[string]interface{})
+			So(box["starMethods"], ShouldResemble, []interface{}{"Box.Join"})
+			fields := box["type"].(map[string]interface{})["fields"].([]interface{})
+			So(fields[2].(map[string]interface{})["type"], ShouldResemble, map[string]interface{}{
+				"kind": "map",
+				"key":  map[string]interface{}{"kind": "ident", "name": "string"},
+				"value": map[string]interface{}{"kind": "slice", "elem": map[string]interface{}{
+					"kind": "star", "elem": map[string]interface{}{"kind": "ident", "name": "Box"},
+				}},
+			})
+			So(fields[4].(map[string]interface{})["type"], ShouldResemble, map[string]interface{}{
+				"kind": "selector", "name": "Handler", "package": "net/http", "alias": "h",
+			})
+		})
+
+		Convey("round trip", func() {
+			p2 := &Package{}
+			So(json.Unmarshal(data, p2), ShouldBeNil)
+			data2, err := json.Marshal(p2)
+			So(err, ShouldBeNil)
+			So(string(data2), ShouldEqual, string(data))
+
+			So(p2.Path, ShouldEqual, "example.com/test")
+			So(p2.Diagnostics[0].Message, ShouldEqual, "a problem")
+			f2 := p2.Files[0]
+			So(f2.Directives[0].Constraint, ShouldNotBeNil)
+			So(f2.Annotations[0].Params["name"], ShouldEqual, "test")
+
+			color, err := p2.FindType("Color")
+			So(err, ShouldBeNil)
+			So(color.Methods[0], ShouldEqual, f2.Functions[1])
+			So(f2.Functions[1].Types[0].Scope, ShouldEqual, f2.Functions[1])
+
+			box, err := p2.FindType("Box")
+			So(err, ShouldBeNil)
+			st := box.Type.(*StructType)
+			So(st.Fields[0].Tags.Get("json"), ShouldEqual, "name")
+			So(st.Fields[3].Type.(*StarType).Target, ShouldHaveSameTypeAs, &EllipsisType{})
+			sel := st.Fields[4].Type.(*SelectorType)
+			So(sel.pkg, ShouldEqual, f2.Imports[1])
+
+			join := box.StarMethods[0]
+			So(join.Type.Variadic, ShouldBeTrue)
+			So(join.Type.Signature("Join", true), ShouldEqual, "func Join(sep string, parts ...string) (res string, err error)")
+
+			d, err := p2.FindConstant("d")
+			So(err, ShouldBeNil)
+			v, err := d.Evaluate()
+			So(err, ShouldBeNil)
+			So(constant.Compare(v, token.EQL, constant.MakeInt64(8)), ShouldBeTrue)
+			blue, err := p2.FindConstant("Blue")
+			So(err, ShouldBeNil)
+			v, err = blue.Evaluate()
+			So(err, ShouldBeNil)
+			So(v.String(), ShouldEqual, "3")
+
+			handle, err := p2.FindVariable("handle")
+			So(err, ShouldBeNil)
+			So(handle.Expression.Function.Name, ShouldEqual, "handle.func1")
+			ch, err := p2.FindVariable("ch")
+			So(err, ShouldBeNil)
+			So(ch.Type.GetDefinition(), ShouldEqual, "chan<- int")
+
+			before, err := NewPrinter(nil).Sprint(f)
+			So(err, ShouldBeNil)
+			after, err := NewPrinter(nil).Sprint(f2)
+			So(err, ShouldBeNil)
+			// the bodies are not in the document
+			So(after, ShouldContainSubstring, "var (\n\t// w is a writer\n\tw      io.Writer\n\tx, y   = pair()\n")
+			So(after, ShouldContainSubstring, "\tc, d\n)")
+			So(before, ShouldContainSubstring, "\tc, d\n)")
+		})
+
+		Convey("errors", func() {
+			p2 := &Package{}
+			So(json.Unmarshal([]byte(`{"schema":"humanize/v0"}`), p2), ShouldNotBeNil)
+			So(json.Unmarshal([]byte(`{"schema":"humanize/v1","files":[{"types":[{"name":"A","type":{"kind":"unknown"}}]}]}`), p2), ShouldNotBeNil)
+			So(json.Unmarshal([]byte(`{"schema":"humanize/v1","files":[{"types":[{"name":"A","type":{"kind":"ident","name":"int"},"methods":["A.X"]}]}]}`), p2), ShouldNotBeNil)
+		})
+	})
+}
@@ -0,0 +1,498 @@
+package humanize
+
+import (
+	"fmt"
+	"go/constant"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// JSONSchemaDraft is the $schema of the generated JSON schemas
+const JSONSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// JSONSchema is a Draft 2020-12 JSON Schema, only the keywords that are used for the
+// go types are here
+type JSONSchema struct {
+	Schema      string `json:"$schema,omitempty"`
+	Ref         string `json:"$ref,omitempty"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	Type        string `json:"type,omitempty"`
+	Format      string `json:"format,omitempty"`
+	// ContentEncoding is base64 for []byte
+	ContentEncoding      string                 `json:"contentEncoding,omitempty"`
+	Enum                 []interface{}          `json:"enum,omitempty"`
+	Properties           map[string]*JSONSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	AdditionalProperties *JSONSchema            `json:"additionalProperties,omitempty"`
+	Items                *JSONSchema            `json:"items,omitempty"`
+	// AnyOf is for the nullable fields, the schema of the type and the null
+	AnyOf            []*JSONSchema          `json:"anyOf,omitempty"`
+	Minimum          *float64               `json:"minimum,omitempty"`
+	Maximum          *float64               `json:"maximum,omitempty"`
+	ExclusiveMinimum *float64               `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum *float64               `json:"exclusiveMaximum,omitempty"`
+	MinLength        *int                   `json:"minLength,omitempty"`
+	MaxLength        *int                   `json:"maxLength,omitempty"`
+	MinItems         *int                   `json:"minItems,omitempty"`
+	MaxItems         *int                   `json:"maxItems,omitempty"`
+	MinProperties    *int                   `json:"minProperties,omitempty"`
+	MaxProperties    *int                   `json:"maxProperties,omitempty"`
+	Pattern          string                 `json:"pattern,omitempty"`
+	Defs             map[string]*JSONSchema `json:"$defs,omitempty"`
+}
+
+// wireField is a field in the JSON encoding of a struct, after the json tag and the
+// promotion of the embedded structs
+type wireField struct {
+	Name  string
+	Field *Field // nil for the embedded types that are not promoted
+	Type  Type
+	Docs  Docs
+	Tags  reflect.StructTag
+	// Package is the package of the struct, the field type is in this package
+	Package   *Package
+	OmitEmpty bool
+	// AsString is for the ,string option
+	AsString bool
+}
+
+// jsonTag return the name and the options of the json tag
+func jsonTag(tag string) (string, []string) {
+	parts := strings.Split(tag, ",")
+	return parts[0], parts[1:]
+}
+
+func hasOption(opts []string, opt string) bool {
+	for _, o := range opts {
+		if o == opt {
+			return true
+		}
+	}
+	return false
+}
+
+// structFields return the fields of the struct like the encoding/json, the names are
+// from the tag with the key, and the fields of the embedded structs are promoted if
+// the embed has no name in the tag. empty key means the go names
+func structFields(st *StructType, p *Package, key string) ([]*wireField, error) {
+	return wireFields(st, p, key, make(map[*TypeName]bool))
+}
+
+// wireFields is the structFields, the embedded types in visited are not promoted
+// again, the structs can embed each other with the pointers
+func wireFields(st *StructType, p *Package, key string, visited map[*TypeName]bool) ([]*wireField, error) {
+	var (
+		res  []*wireField
+		seen = make(map[string]bool)
+	)
+	add := func(wf *wireField, tag string) {
+		name, opts := jsonTag(tag)
+		if name == "-" && len(opts) == 0 {
+			return
+		}
+		if name != "" {
+			wf.Name = name
+		}
+		wf.OmitEmpty = hasOption(opts, "omitempty")
+		wf.AsString = hasOption(opts, "string")
+		if !seen[wf.Name] {
+			seen[wf.Name] = true
+			res = append(res, wf)
+		}
+	}
+	for _, f := range st.Fields {
+		if !isExported(f.Name) {
+			continue
+		}
+		docs := f.Docs
+		if len(docs) == 0 {
+			docs = f.Comment
+		}
+		add(&wireField{Name: f.Name, Field: f, Type: f.Type, Docs: docs, Tags: f.Tags, Package: p}, f.Tags.Get(key))
+	}
+	// the embedded fields are after the direct ones, the shallower one wins
+	for _, e := range st.Embeds {
+		tag := e.Tags.Get(key)
+		t := e.Type
+		if s, ok := t.(*StarType); ok {
+			t = s.Target
+		}
+		tn, tp := newTyper(p, nil).typeName(t)
+		if name, _ := jsonTag(tag); name == "" && tn != nil {
+			if est, ok := tn.Type.(*StructType); ok {
+				if visited[tn] {
+					continue
+				}
+				visited[tn] = true
+				fields, err := wireFields(est, tp, key, visited)
+				if err != nil {
+					return nil, err
+				}
+				for _, wf := range fields {
+					if !seen[wf.Name] {
+						seen[wf.Name] = true
+						res = append(res, wf)
+					}
+				}
+				continue
+			}
+		}
+		name := removeReceiver(removeStar(e.Type.GetDefinition()))
+		if !isExported(name) {
+			continue
+		}
+		docs := e.Docs
+		if len(docs) == 0 {
+			docs = e.Comment
+		}
+		add(&wireField{Name: name, Type: e.Type, Docs: docs, Tags: e.Tags, Package: p}, tag)
+	}
+	return res, nil
+}
+
+// namedCollection return the slice or map of the named slices and maps, like type IDs
+// []string, and the package that it is in. the other types are not changed
+func namedCollection(t Type, p *Package) (Type, *Package) {
+	seen := make(map[*TypeName]bool)
+	for nt, np := t, p; ; {
+		tn, tp := newTyper(np, nil).typeName(nt)
+		if tn == nil || seen[tn] {
+			return t, p
+		}
+		seen[tn] = true
+		switch tn.Type.(type) {
+		case *ArrayType, *MapType:
+			return tn.Type, tp
+		}
+		nt, np = tn.Type, tp
+	}
+}
+
+// schemaBuilder create the schema, the named types are in the $defs
+type schemaBuilder struct {
+	pkg  *Package
+	defs map[string]*JSONSchema
+	refs map[*TypeName]string
+	// prefix is the prefix of the references, like #/$defs/
+	prefix string
+}
+
+// NewJSONSchema create the JSON schema of the type in the package. the named types are
+// in $defs, the doc comments are the descriptions, the enum constants are the enum
+// and the validate tags are converted to the constraints if there is a keyword for
+// them. the pointer, slice and map fields without omitempty accept null, unless they
+// are required in the validate tag
+func NewJSONSchema(p *Package, name string) (*JSONSchema, error) {
+	tn, err := p.FindType(name)
+	if err != nil {
+		return nil, err
+	}
+	sb := &schemaBuilder{
+		pkg:    p,
+		defs:   make(map[string]*JSONSchema),
+		refs:   map[*TypeName]string{tn: "#"},
+		prefix: "#/$defs/",
+	}
+	res, err := sb.typeName(tn, p)
+	if err != nil {
+		return nil, err
+	}
+	res.Schema = JSONSchemaDraft
+	res.Title = tn.Name
+	if len(sb.defs) > 0 {
+		res.Defs = sb.defs
+	}
+	return res, nil
+}
+
+// typeName return the schema of the named type, with the enum values
+func (sb *schemaBuilder) typeName(tn *TypeName, p *Package) (*JSONSchema, error) {
+	res, err := sb.schema(tn.Type, p)
+	if err != nil {
+		return nil, err
+	}
+	res.Description = description(tn.Docs)
+	if e, err := p.FindEnum(tn.Name); err == nil {
+		for _, v := range e.Values {
+			if v.Value != nil {
+				res.Enum = append(res.Enum, enumValue(v.Value))
+			}
+		}
+	}
+	return res, nil
+}
+
+// enumValue return the go value of the constant for the json encoding
+func enumValue(v constant.Value) interface{} {
+	switch v.Kind() {
+	case constant.Int:
+		if i, ok := constant.Int64Val(v); ok {
+			return i
+		}
+		f, _ := constant.Float64Val(v)
+		return f
+	case constant.Float:
+		f, _ := constant.Float64Val(v)
+		return f
+	}
+	return constant.Val(v)
+}
+
+// named return the reference to the named type, or the schema of the special types
+func (sb *schemaBuilder) named(t Type, p *Package) (*JSONSchema, error) {
+	if sel, ok := t.(*SelectorType); ok && sel.pkg != nil {
+		switch sel.pkg.Path + "." + sel.Type.GetDefinition() {
+		case "time.Time":
+			return &JSONSchema{Type: "string", Format: "date-time"}, nil
+		case "time.Duration":
+			return &JSONSchema{Type: "integer"}, nil
+		case "encoding/json.RawMessage":
+			return &JSONSchema{}, nil
+		}
+	}
+	tn, tp := newTyper(p, nil).typeName(t)
+	if tn == nil {
+		return nil, fmt.Errorf("type %s not found", t.GetDefinition())
+	}
+	return sb.define(tn, tp)
+}
+
+// define add the named type to the definitions and return the reference to it
+func (sb *schemaBuilder) define(tn *TypeName, tp *Package) (*JSONSchema, error) {
+	if ref, ok := sb.refs[tn]; ok {
+		return &JSONSchema{Ref: ref}, nil
+	}
+	key := tn.Name
+	if tp != sb.pkg {
+		key = tp.Name + "." + tn.Name
+	}
+	ref := sb.prefix + key
+	// add it before the schema, for the recursive types
+	sb.refs[tn] = ref
+	def, err := sb.typeName(tn, tp)
+	if err != nil {
+		return nil, err
+	}
+	sb.defs[key] = def
+	return &JSONSchema{Ref: ref}, nil
+}
+
+var basicSchemas = map[string]string{
+	"string": "string", "bool": "boolean",
+	"int": "integer", "int8": "integer", "int16": "integer", "int32": "integer", "int64": "integer",
+	"uint": "integer", "uint8": "integer", "uint16": "integer", "uint32": "integer", "uint64": "integer",
+	"uintptr": "integer", "byte": "integer", "rune": "integer",
+	"float32": "number", "float64": "number",
+}
+
+func (sb *schemaBuilder) schema(t Type, p *Package) (*JSONSchema, error) {
+	switch x := t.(type) {
+	case nil:
+		return nil, fmt.Errorf("nil type")
+	case *IdentType:
+		if s, ok := basicSchemas[x.Ident]; ok {
+			res := &JSONSchema{Type: s}
+			if strings.HasPrefix(x.Ident, "uint") || x.Ident == "byte" {
+				zero := 0.0
+				res.Minimum = &zero
+			}
+			return res, nil
+		}
+		if x.Ident == "any" {
+			return &JSONSchema{}, nil
+		}
+		if predeclared[x.Ident] {
+			return nil, fmt.Errorf("type %s is not supported in json", x.Ident)
+		}
+		return sb.named(x, p)
+	case *SelectorType:
+		return sb.named(x, p)
+	case *StarType:
+		return sb.schema(x.Target, p)
+	case *EllipsisType:
+		return sb.schema(x.ArrayType, p)
+	case *ArrayType:
+		if x.Slice {
+			if id, ok := x.Type.(*IdentType); ok && (id.Ident == "byte" || id.Ident == "uint8") {
+				return &JSONSchema{Type: "string", ContentEncoding: "base64"}, nil
+			}
+		}
+		items, err := sb.schema(x.Type, p)
+		if err != nil {
+			return nil, err
+		}
+		res := &JSONSchema{Type: "array", Items: items}
+		if !x.Slice {
+			n := x.Len
+			res.MinItems, res.MaxItems = &n, &n
+		}
+		return res, nil
+	case *MapType:
+		value, err := sb.schema(x.Value, p)
+		if err != nil {
+			return nil, err
+		}
+		return &JSONSchema{Type: "object", AdditionalProperties: value}, nil
+	case *InterfaceType:
+		// any value
+		return &JSONSchema{}, nil
+	case *StructType:
+		return sb.object(x, p)
+	}
+	return nil, fmt.Errorf("type %s is not supported in json", t.GetDefinition())
+}
+
+func (sb *schemaBuilder) object(st *StructType, p *Package) (*JSONSchema, error) {
+	fields, err := structFields(st, p, "json")
+	if err != nil {
+		return nil, err
+	}
+	res := &JSONSchema{Type: "object", Properties: make(map[string]*JSONSchema)}
+	for _, wf := range fields {
+		s, err := sb.schema(wf.Type, wf.Package)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", wf.Name, err)
+		}
+		if wf.AsString && s.Ref == "" && (s.Type == "integer" || s.Type == "number" || s.Type == "boolean") {
+			s = &JSONSchema{Type: "string"}
+		}
+		var nonNil bool
+		if v := wf.Tags.Get("validate"); v != "" {
+			nonNil = validate(s, strings.Split(v, ","))
+		}
+		// encoding/json write the nil pointers, slices and maps as null, the omitempty
+		// ones are not written
+		if !nonNil && !wf.OmitEmpty && nilable(wf.Type, wf.Package) {
+			s = &JSONSchema{AnyOf: []*JSONSchema{s, {Type: "null"}}}
+		}
+		if d := description(wf.Docs); d != "" {
+			if s.Ref != "" {
+				// the $ref is a reference to the shared one, the field description
+				// should not change it
+				s = &JSONSchema{Ref: s.Ref}
+			}
+			s.Description = d
+		}
+		if nonNil || !wf.OmitEmpty {
+			res.Required = append(res.Required, wf.Name)
+		}
+		res.Properties[wf.Name] = s
+	}
+	return res, nil
+}
+
+// nilable return true for the pointers, the slices and the maps
+func nilable(t Type, p *Package) bool {
+	if _, ok := t.(*StarType); ok {
+		return true
+	}
+	switch x, _ := namedCollection(t, p); x := x.(type) {
+	case *ArrayType:
+		return x.Slice
+	case *EllipsisType, *MapType:
+		return true
+	}
+	return false
+}
+
+// validate apply the rules of the validate tag, the ones without a keyword are
+// ignored. the rules after dive are for the items. it return true for the required
+// ones
+func validate(s *JSONSchema, rules []string) bool {
+	var required bool
+	for i, rule := range rules {
+		parts := strings.SplitN(rule, "=", 2)
+		name, arg := parts[0], ""
+		if len(parts) == 2 {
+			arg = parts[1]
+		}
+		if name == "dive" {
+			// the rest is for the items
+			if s.Items != nil {
+				validate(s.Items, rules[i+1:])
+			} else if s.AdditionalProperties != nil {
+				validate(s.AdditionalProperties, rules[i+1:])
+			}
+			break
+		}
+		num, err := strconv.ParseFloat(arg, 64)
+		isNum := err == nil
+		count := int(num)
+		switch {
+		case name == "required":
+			required = true
+		case name == "oneof":
+			for _, v := range strings.Fields(arg) {
+				if s.Type == "integer" || s.Type == "number" {
+					if n, err := strconv.ParseFloat(v, 64); err == nil {
+						s.Enum = append(s.Enum, n)
+						continue
+					}
+				}
+				s.Enum = append(s.Enum, v)
+			}
+		case (name == "min" || name == "max" || name == "len") && isNum:
+			switch s.Type {
+			case "string":
+				if name != "max" {
+					s.MinLength = &count
+				}
+				if name != "min" {
+					s.MaxLength = &count
+				}
+			case "array":
+				if name != "max" {
+					s.MinItems = &count
+				}
+				if name != "min" {
+					s.MaxItems = &count
+				}
+			case "object":
+				if name != "max" {
+					s.MinProperties = &count
+				}
+				if name != "min" {
+					s.MaxProperties = &count
+				}
+			case "integer", "number":
+				if name != "max" {
+					s.Minimum = &num
+				}
+				if name != "min" {
+					s.Maximum = &num
+				}
+			}
+		case (name == "gt" || name == "gte" || name == "lt" || name == "lte") && isNum:
+			if s.Type != "integer" && s.Type != "number" {
+				continue
+			}
+			switch name {
+			case "gt":
+				s.ExclusiveMinimum = &num
+			case "gte":
+				s.Minimum = &num
+			case "lt":
+				s.ExclusiveMaximum = &num
+			case "lte":
+				s.Maximum = &num
+			}
+		case name == "email":
+			s.Format = "email"
+		case name == "url" || name == "uri":
+			s.Format = "uri"
+		case name == "uuid":
+			s.Format = "uuid"
+		case name == "ipv4" || name == "ipv6" || name == "hostname":
+			s.Format = name
+		case name == "alpha":
+			s.Pattern = "^[a-zA-Z]*$"
+		case name == "alphanum":
+			s.Pattern = "^[a-zA-Z0-9]*$"
+		case name == "numeric":
+			s.Pattern = "^[-+]?[0-9]+(?:\\.[0-9]+)?$"
+		}
+	}
+	return required
+}
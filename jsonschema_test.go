@@ -0,0 +1,185 @@
+package humanize
+
+import (
+	"encoding/json"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+var schemaSrc = `package test
+
+import (
+	"image"
+	"time"
+)
+
+// Status is the state of the order
+type Status string
+
+const (
+	Open   Status = "open"
+	Closed Status = "closed"
+)
+
+// Base is the shared fields
+type Base struct {
+	ID      int       ` + "`json:\"id\"`" + `
+	Created time.Time ` + "`json:\"created\"`" + `
+}
+
+// Order is an order
+// @Table orders
+type Order struct {
+	Base
+	// Name of the order
+	Name   string            ` + "`json:\"name\" validate:\"required,min=3,max=20\"`" + `
+	Email  string            ` + "`json:\"email,omitempty\" validate:\"email\"`" + `
+	Count  uint              ` + "`json:\"count,string\"`" + `
+	Price  float64           ` + "`json:\"price,omitempty\" validate:\"gt=0,lte=100\"`" + `
+	Status Status            ` + "`json:\"status\"`" + `
+	Tags   []string          ` + "`json:\"tags,omitempty\" validate:\"max=5,dive,oneof=a b\"`" + `
+	Data   []byte            ` + "`json:\"data\"`" + `
+	Meta   map[string]any    ` + "`json:\"meta,omitempty\"`" + `
+	Parent *Order            ` + "`json:\"parent,omitempty\"`" + `
+	Items  []*Item           ` + "`json:\"items\"`" + `
+	Where  image.Point       ` + "`json:\"where\"`" + `
+	Notes  []string          ` + "`json:\"notes\" validate:\"required,dive\"`" + `
+	Skip   string            ` + "`json:\"-\"`" + `
+	Dash   string            ` + "`json:\"-,\"`" + `
+	hidden string
+}
+
+// Item is a line of the order
+type Item struct {
+	Order *Order
+	Box   [2]int
+}
+
+type Bad struct {
+	C chan int
+}
+
+type Nullable struct {
+	Ptr  *int
+	S    []string
+	M    map[string]int
+	Tags IDs
+	Box  [2]int
+	Req  *int ` + "`validate:\"required\"`" + `
+}
+
+type IDs []string
+
+type Left struct {
+	*Right
+	Name string
+}
+
+type Right struct {
+	*Left
+	Age int
+}
+`
+
+func TestJSONSchema(t *testing.T) {
+	Convey("JSON schema test", t, func() {
+		var p = &Package{Path: "example.com/test", Name: "test"}
+		f, err := ParseFile(schemaSrc, p)
+		So(err, ShouldBeNil)
+		p.Files = append(p.Files, f)
+		So(lateBind(p), ShouldBeNil)
+
+		s, err := NewJSONSchema(p, "Order")
+		So(err, ShouldBeNil)
+		So(s.Schema, ShouldEqual, JSONSchemaDraft)
+		So(s.Title, ShouldEqual, "Order")
+		// the annotations are not in the description
+		So(s.Description, ShouldEqual, "Order is an order")
+		So(s.Type, ShouldEqual, "object")
+		So(s.Required, ShouldResemble, []string{"name", "count", "status", "data", "items", "where", "notes", "-", "id", "created"})
+
+		Convey("fields", func() {
+			props := s.Properties
+			So(props, ShouldNotContainKey, "Skip")
+			So(props, ShouldNotContainKey, "hidden")
+			So(props, ShouldNotContainKey, "Base")
+			So(props["-"].Type, ShouldEqual, "string")
+			So(props["id"].Type, ShouldEqual, "integer")
+			So(props["created"], ShouldResemble, &JSONSchema{Type: "string", Format: "date-time"})
+			So(props["name"].Description, ShouldEqual, "Name of the order")
+			So(*props["name"].MinLength, ShouldEqual, 3)
+			So(*props["name"].MaxLength, ShouldEqual, 20)
+			So(props["email"].Format, ShouldEqual, "email")
+			So(props["count"], ShouldResemble, &JSONSchema{Type: "string"})
+			So(*props["price"].ExclusiveMinimum, ShouldEqual, 0)
+			So(*props["price"].Maximum, ShouldEqual, 100)
+			So(props["status"].Ref, ShouldEqual, "#/$defs/Status")
+			So(*props["tags"].MaxItems, ShouldEqual, 5)
+			So(props["tags"].Items.Enum, ShouldResemble, []interface{}{"a", "b"})
+			// nil is null without the omitempty
+			So(props["data"], ShouldResemble, &JSONSchema{AnyOf: []*JSONSchema{{Type: "string", ContentEncoding: "base64"}, {Type: "null"}}})
+			So(props["meta"].AdditionalProperties, ShouldResemble, &JSONSchema{})
+			So(props["parent"].Ref, ShouldEqual, "#")
+			So(props["items"].AnyOf[0].Items.Ref, ShouldEqual, "#/$defs/Item")
+			So(props["where"].Ref, ShouldEqual, "#/$defs/image.Point")
+			So(props["notes"], ShouldResemble, &JSONSchema{Type: "array", Items: &JSONSchema{Type: "string"}})
+		})
+
+		Convey("defs", func() {
+			So(s.Defs["Status"].Type, ShouldEqual, "string")
+			So(s.Defs["Status"].Description, ShouldEqual, "Status is the state of the order")
+			So(s.Defs["Status"].Enum, ShouldResemble, []interface{}{"open", "closed"})
+
+			item := s.Defs["Item"]
+			So(item.Properties["Order"].AnyOf[0].Ref, ShouldEqual, "#")
+			So(*item.Properties["Box"].MinItems, ShouldEqual, 2)
+			So(*item.Properties["Box"].MaxItems, ShouldEqual, 2)
+			So(item.Properties["Box"].Items.Type, ShouldEqual, "integer")
+
+			So(s.Defs["image.Point"].Properties, ShouldContainKey, "X")
+
+			data, err := json.Marshal(s)
+			So(err, ShouldBeNil)
+			So(string(data), ShouldStartWith, `{"$schema":"https://json-schema.org/draft/2020-12/schema","title":"Order"`)
+		})
+
+		Convey("recursive defs", func() {
+			s, err := NewJSONSchema(p, "Item")
+			So(err, ShouldBeNil)
+			So(s.Properties["Order"].AnyOf[0].Ref, ShouldEqual, "#/$defs/Order")
+			So(s.Defs["Order"].Properties["items"].AnyOf[0].Items.Ref, ShouldEqual, "#")
+		})
+
+		Convey("nullable", func() {
+			s, err := NewJSONSchema(p, "Nullable")
+			So(err, ShouldBeNil)
+			null := &JSONSchema{Type: "null"}
+			So(s.Properties["Ptr"], ShouldResemble, &JSONSchema{AnyOf: []*JSONSchema{{Type: "integer"}, null}})
+			So(s.Properties["S"], ShouldResemble, &JSONSchema{AnyOf: []*JSONSchema{{Type: "array", Items: &JSONSchema{Type: "string"}}, null}})
+			So(s.Properties["M"].AnyOf[1], ShouldResemble, null)
+			So(s.Properties["Tags"], ShouldResemble, &JSONSchema{AnyOf: []*JSONSchema{{Ref: "#/$defs/IDs"}, null}})
+			So(s.Properties["Box"].AnyOf, ShouldBeNil)
+			So(s.Properties["Req"], ShouldResemble, &JSONSchema{Type: "integer"})
+		})
+
+		Convey("mutual embeds", func() {
+			s, err := NewJSONSchema(p, "Left")
+			So(err, ShouldBeNil)
+			So(s.Properties, ShouldContainKey, "Name")
+			So(s.Properties, ShouldContainKey, "Age")
+			So(len(s.Properties), ShouldEqual, 2)
+
+			// the other exports use the same fields
+			_, err = TypeScript(p, "Right")
+			So(err, ShouldBeNil)
+		})
+
+		Convey("errors", func() {
+			_, err := NewJSONSchema(p, "Bad")
+			So(err, ShouldNotBeNil)
+			_, err = NewJSONSchema(p, "Missing")
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
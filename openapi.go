@@ -0,0 +1,404 @@
+package humanize
+
+import (
+	"fmt"
+	"go/ast"
+	"go/doc"
+	"go/parser"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// OpenAPIVersion is the version of the generated OpenAPI documents
+const OpenAPIVersion = "3.1.0"
+
+// OpenAPI is an OpenAPI 3.1 document, only the parts that are created from the
+// package are here
+type OpenAPI struct {
+	OpenAPI    string                                  `json:"openapi"`
+	Info       OpenAPIInfo                             `json:"info"`
+	Paths      map[string]map[string]*OpenAPIOperation `json:"paths,omitempty"`
+	Components OpenAPIComponents                       `json:"components"`
+}
+
+// OpenAPIInfo is the info of the document, the title is the package name and the
+// description is the package docs
+type OpenAPIInfo struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// OpenAPIOperation is a single operation, from a function with the @Route annotation
+type OpenAPIOperation struct {
+	OperationID string                      `json:"operationId,omitempty"`
+	Summary     string                      `json:"summary,omitempty"`
+	Description string                      `json:"description,omitempty"`
+	Tags        []string                    `json:"tags,omitempty"`
+	Parameters  []*OpenAPIParameter         `json:"parameters,omitempty"`
+	RequestBody *OpenAPIRequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*OpenAPIResponse `json:"responses"`
+}
+
+// OpenAPIParameter is a path, query, header or cookie parameter
+type OpenAPIParameter struct {
+	Name        string      `json:"name"`
+	In          string      `json:"in"`
+	Description string      `json:"description,omitempty"`
+	Required    bool        `json:"required,omitempty"`
+	Schema      *JSONSchema `json:"schema"`
+}
+
+// OpenAPIRequestBody is the body of the request
+type OpenAPIRequestBody struct {
+	Description string                       `json:"description,omitempty"`
+	Required    bool                         `json:"required,omitempty"`
+	Content     map[string]*OpenAPIMediaType `json:"content"`
+}
+
+// OpenAPIMediaType is the schema of the body
+type OpenAPIMediaType struct {
+	Schema *JSONSchema `json:"schema"`
+}
+
+// OpenAPIResponse is a single response of the operation
+type OpenAPIResponse struct {
+	Description string                       `json:"description"`
+	Content     map[string]*OpenAPIMediaType `json:"content,omitempty"`
+}
+
+// OpenAPIComponents is the shared schemas, the $refs are to them
+type OpenAPIComponents struct {
+	Schemas map[string]*JSONSchema `json:"schemas,omitempty"`
+}
+
+const jsonMediaType = "application/json"
+
+var pathParam = regexp.MustCompile(`{([^}]+)}`)
+
+// openAPIBuilder create the operations, the schemas are in the components
+type openAPIBuilder struct {
+	pkg *Package
+	sb  *schemaBuilder
+	res *OpenAPI
+}
+
+// NewOpenAPI create the OpenAPI document of the package. the paths are from the
+// functions and the methods with the annotations:
+//
+//	@Route METHOD /path/{param} [id=operationId] [tags=a,b]
+//	@Param name in [type] [desc="description"] [required=true]
+//	@Success code [type] [desc="description"]
+//	@Failure code [type] [desc="description"]
+//
+// the in is path, query, header, cookie or body. the types are go types in the
+// package, the only optional argument is the type so the description is always the
+// desc key. without the types the type of the function parameter with the same name is
+// used, the request body is the first struct parameter and the response is the first
+// result that is not an error, it is the 200 response without the @Success. the
+// schemas of the used types and the types with the @Schema annotation are in the
+// components
+func NewOpenAPI(p *Package) (*OpenAPI, error) {
+	ob := &openAPIBuilder{
+		pkg: p,
+		sb: &schemaBuilder{
+			pkg:    p,
+			defs:   make(map[string]*JSONSchema),
+			refs:   make(map[*TypeName]string),
+			prefix: "#/components/schemas/",
+		},
+		res: &OpenAPI{
+			OpenAPI: OpenAPIVersion,
+			Info:    OpenAPIInfo{Title: p.Name, Version: "0.0.0"},
+			Paths:   make(map[string]map[string]*OpenAPIOperation),
+		},
+	}
+	for _, f := range p.Files {
+		if d := description(f.Docs); d != "" {
+			ob.res.Info.Description = d
+		}
+		for _, tn := range f.Types {
+			if _, ok := tn.Annotations.Find("Schema"); !ok {
+				continue
+			}
+			if _, err := ob.sb.define(tn, p); err != nil {
+				return nil, fmt.Errorf("type %s: %w", tn.Name, err)
+			}
+		}
+	}
+	for _, f := range p.Files {
+		for _, fn := range f.Functions {
+			for _, route := range fn.Annotations.FindAll("Route") {
+				if err := ob.operation(fn, route); err != nil {
+					return nil, fmt.Errorf("%s: %s: %w", route.Pos, fn.Name, err)
+				}
+			}
+		}
+	}
+	if len(ob.sb.defs) > 0 {
+		ob.res.Components.Schemas = ob.sb.defs
+	}
+	return ob.res, nil
+}
+
+// annotationType parse the go type in the annotation, in the function file
+func (ob *openAPIBuilder) annotationType(fn *Function, src string) (Type, error) {
+	e, err := parser.ParseExpr(src)
+	if err != nil {
+		return nil, fmt.Errorf("invalid type %s", src)
+	}
+	valid := true
+	ast.Inspect(e, func(n ast.Node) bool {
+		switch x := n.(type) {
+		case *ast.Ident, *ast.StarExpr, *ast.ArrayType, *ast.MapType, *ast.InterfaceType, *ast.FieldList:
+		case *ast.SelectorExpr:
+			_, ok := x.X.(*ast.Ident)
+			valid = valid && ok
+		case nil:
+		default:
+			valid = false
+		}
+		return valid
+	})
+	if !valid {
+		return nil, fmt.Errorf("invalid type %s", src)
+	}
+	return getType(e, src, fn.file, ob.pkg), nil
+}
+
+// parameter return the function parameter with the name
+func parameter(fn *Function, name string) *Variable {
+	for _, v := range fn.Type.Parameters {
+		if v.Name == name {
+			return v
+		}
+	}
+	return nil
+}
+
+// bodyParameter return the first parameter that is a struct, the context and the
+// net/http types are not the body
+func (ob *openAPIBuilder) bodyParameter(fn *Function) *Variable {
+	for _, v := range fn.Type.Parameters {
+		t := v.Type
+		if s, ok := t.(*StarType); ok {
+			t = s.Target
+		}
+		if sel, ok := t.(*SelectorType); ok && sel.pkg != nil && (sel.pkg.Path == "context" || sel.pkg.Path == "net/http") {
+			continue
+		}
+		if tn, _ := newTyper(ob.pkg, nil).typeName(t); tn != nil {
+			if _, ok := tn.Type.(*StructType); ok {
+				return v
+			}
+		}
+	}
+	return nil
+}
+
+// resultType return the first result that is not an error
+func resultType(fn *Function) Type {
+	for _, v := range fn.Type.Results {
+		if id, ok := v.Type.(*IdentType); ok && id.Ident == "error" {
+			continue
+		}
+		return v.Type
+	}
+	return nil
+}
+
+func (ob *openAPIBuilder) operation(fn *Function, route *Annotation) error {
+	if len(route.Args) != 2 {
+		return fmt.Errorf("the @Route needs the method and the path")
+	}
+	method, path := strings.ToUpper(route.Args[0]), route.Args[1]
+	switch method {
+	case http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete,
+		http.MethodHead, http.MethodOptions, http.MethodTrace:
+	default:
+		return fmt.Errorf("invalid method %s", route.Args[0])
+	}
+	if !strings.HasPrefix(path, "/") {
+		return fmt.Errorf("invalid path %s", path)
+	}
+	item, ok := ob.res.Paths[path]
+	if !ok {
+		item = make(map[string]*OpenAPIOperation)
+		ob.res.Paths[path] = item
+	}
+	key := strings.ToLower(method)
+	if _, ok := item[key]; ok {
+		return fmt.Errorf("there is more than one operation for %s %s", method, path)
+	}
+
+	desc := description(fn.Docs)
+	var dp doc.Package
+	op := &OpenAPIOperation{
+		OperationID: strings.Replace(fn.Name, ".", "", -1),
+		Summary:     dp.Synopsis(desc),
+		Description: desc,
+		Responses:   make(map[string]*OpenAPIResponse),
+	}
+	if id, ok := route.Params["id"]; ok {
+		op.OperationID = id
+	}
+	if tags, ok := route.Params["tags"]; ok {
+		op.Tags = strings.Split(tags, ",")
+	}
+
+	if err := ob.parameters(op, fn, path); err != nil {
+		return err
+	}
+	if op.RequestBody == nil && (method == http.MethodPost || method == http.MethodPut || method == http.MethodPatch) {
+		if v := ob.bodyParameter(fn); v != nil {
+			if err := ob.body(op, v.Type, description(v.Docs)); err != nil {
+				return err
+			}
+		}
+	}
+	if err := ob.responses(op, fn); err != nil {
+		return err
+	}
+	item[key] = op
+	return nil
+}
+
+func (ob *openAPIBuilder) body(op *OpenAPIOperation, t Type, desc string) error {
+	s, err := ob.sb.schema(t, ob.pkg)
+	if err != nil {
+		return err
+	}
+	op.RequestBody = &OpenAPIRequestBody{
+		Description: desc,
+		Required:    true,
+		Content:     map[string]*OpenAPIMediaType{jsonMediaType: {Schema: s}},
+	}
+	return nil
+}
+
+// parameters add the @Param parameters, and the path parameters that are not there
+func (ob *openAPIBuilder) parameters(op *OpenAPIOperation, fn *Function, path string) error {
+	declared := make(map[string]bool)
+	for _, a := range fn.Annotations.FindAll("Param") {
+		if len(a.Args) < 2 || len(a.Args) > 3 {
+			return fmt.Errorf("the @Param needs the name, the in, and the optional type")
+		}
+		name, in, desc := a.Args[0], a.Args[1], a.Params["desc"]
+		var t Type
+		if len(a.Args) > 2 {
+			var err error
+			if t, err = ob.annotationType(fn, a.Args[2]); err != nil {
+				return err
+			}
+		}
+		if t == nil {
+			if v := parameter(fn, name); v != nil {
+				t = v.Type
+			}
+		}
+		if in == "body" {
+			if t == nil {
+				return fmt.Errorf("the type of the body %s is not known", name)
+			}
+			if err := ob.body(op, t, desc); err != nil {
+				return err
+			}
+			continue
+		}
+		switch in {
+		case "path", "query", "header", "cookie":
+		default:
+			return fmt.Errorf("invalid parameter location %s", in)
+		}
+		param, err := ob.parameter(name, in, t)
+		if err != nil {
+			return err
+		}
+		param.Description = desc
+		param.Required = in == "path" || a.Params["required"] == "true"
+		declared[in+"/"+name] = true
+		op.Parameters = append(op.Parameters, param)
+	}
+	for _, m := range pathParam.FindAllStringSubmatch(path, -1) {
+		name := m[1]
+		if declared["path/"+name] {
+			continue
+		}
+		var t Type
+		if v := parameter(fn, name); v != nil {
+			t = v.Type
+		}
+		param, err := ob.parameter(name, "path", t)
+		if err != nil {
+			return err
+		}
+		param.Required = true
+		op.Parameters = append(op.Parameters, param)
+	}
+	return nil
+}
+
+// parameter create the parameter, it is a string if the type is not known
+func (ob *openAPIBuilder) parameter(name, in string, t Type) (*OpenAPIParameter, error) {
+	s := &JSONSchema{Type: "string"}
+	if t != nil {
+		var err error
+		if s, err = ob.sb.schema(t, ob.pkg); err != nil {
+			return nil, fmt.Errorf("parameter %s: %w", name, err)
+		}
+	}
+	return &OpenAPIParameter{Name: name, In: in, Schema: s}, nil
+}
+
+// responses add the @Success and @Failure responses
+func (ob *openAPIBuilder) responses(op *OpenAPIOperation, fn *Function) error {
+	for _, a := range append(fn.Annotations.FindAll("Success"), fn.Annotations.FindAll("Failure")...) {
+		if len(a.Args) < 1 || len(a.Args) > 2 {
+			return fmt.Errorf("the @%s needs the status code, and the optional type", a.Name)
+		}
+		code, err := strconv.Atoi(a.Args[0])
+		if err != nil || code < 100 || code > 599 {
+			return fmt.Errorf("invalid status code %s", a.Args[0])
+		}
+		var t Type
+		if len(a.Args) > 1 {
+			if t, err = ob.annotationType(fn, a.Args[1]); err != nil {
+				return err
+			}
+		} else if a.Name == "Success" && code != http.StatusNoContent {
+			t = resultType(fn)
+		}
+		resp := &OpenAPIResponse{Description: http.StatusText(code)}
+		if desc, ok := a.Params["desc"]; ok {
+			resp.Description = desc
+		}
+		if t != nil {
+			s, err := ob.sb.schema(t, ob.pkg)
+			if err != nil {
+				return err
+			}
+			resp.Content = map[string]*OpenAPIMediaType{jsonMediaType: {Schema: s}}
+		}
+		op.Responses[a.Args[0]] = resp
+	}
+	if len(op.Responses) > 0 {
+		return nil
+	}
+	// the responses is required, without the annotations it is the result of the
+	// function
+	if t := resultType(fn); t != nil {
+		s, err := ob.sb.schema(t, ob.pkg)
+		if err != nil {
+			return err
+		}
+		op.Responses["200"] = &OpenAPIResponse{
+			Description: http.StatusText(http.StatusOK),
+			Content:     map[string]*OpenAPIMediaType{jsonMediaType: {Schema: s}},
+		}
+		return nil
+	}
+	op.Responses["default"] = &OpenAPIResponse{Description: "the response"}
+	return nil
+}
@@ -0,0 +1,180 @@
+package humanize
+
+import (
+	"encoding/json"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+var openAPISrc = `// Package api is the users api
+package api
+
+import (
+	"context"
+	"net/http"
+)
+
+// User is a user
+type User struct {
+	ID   int    ` + "`json:\"id\"`" + `
+	Name string ` + "`json:\"name\"`" + `
+}
+
+// CreateUser is the request to create a user
+type CreateUser struct {
+	Name string ` + "`json:\"name\" validate:\"required\"`" + `
+}
+
+// Error is the error response
+// @Schema
+type Error struct {
+	Message string ` + "`json:\"message\"`" + `
+}
+
+type Server struct{}
+
+// GetUser return the user. It is cached.
+// @Route GET /users/{id} tags=users
+// @Param verbose query bool desc="more fields"
+// @Success 200
+// @Failure 404 Error desc="not found"
+func (s *Server) GetUser(ctx context.Context, id int) (*User, error) {
+	return nil, nil
+}
+
+// CreateUser create the user
+// @Route POST /users id=createUser tags=users,admin
+// @Success 201 User
+func (s *Server) CreateUser(ctx context.Context, r *http.Request, req *CreateUser) (*User, error) {
+	return nil, nil
+}
+
+// @Route DELETE /users/{id}
+// @Param id path desc="the user id"
+// @Success 204 desc=deleted
+func DeleteUser(w http.ResponseWriter, r *http.Request) {
+}
+
+// @Route GET /users
+func ListUsers() ([]User, error) {
+	return nil, nil
+}
+
+// @Route PUT /users/{id}
+// @Param user body []User
+func ReplaceUsers(w http.ResponseWriter, r *http.Request) {
+}
+`
+
+var openAPIBad = `package api
+
+// @Route GET /a
+// @Success 200 1+2
+func Bad() {}
+`
+
+// the description is not positional, so it is not taken as the type
+var openAPIPositional = map[string]string{
+	"DeleteUser: invalid type the user id": `package api
+
+// @Route DELETE /users/{id}
+// @Param id path "the user id"
+func DeleteUser() {}
+`,
+	"DeletePost: the @Success needs the status code, and the optional type": `package api
+
+// @Route DELETE /posts/{id}
+// @Success 204 int "deleted"
+func DeletePost() {}
+`,
+}
+
+func TestOpenAPI(t *testing.T) {
+	Convey("OpenAPI test", t, func() {
+		var p = &Package{Path: "example.com/api", Name: "api"}
+		f, err := ParseFile(openAPISrc, p)
+		So(err, ShouldBeNil)
+		p.Files = append(p.Files, f)
+		So(lateBind(p), ShouldBeNil)
+
+		doc, err := NewOpenAPI(p)
+		So(err, ShouldBeNil)
+		So(doc.OpenAPI, ShouldEqual, "3.1.0")
+		So(doc.Info, ShouldResemble, OpenAPIInfo{Title: "api", Description: "Package api is the users api", Version: "0.0.0"})
+		So(doc.Paths, ShouldContainKey, "/users/{id}")
+		So(doc.Paths, ShouldContainKey, "/users")
+
+		Convey("get", func() {
+			op := doc.Paths["/users/{id}"]["get"]
+			So(op.OperationID, ShouldEqual, "ServerGetUser")
+			So(op.Summary, ShouldEqual, "GetUser return the user.")
+			So(op.Description, ShouldEqual, "GetUser return the user. It is cached.")
+			So(op.Tags, ShouldResemble, []string{"users"})
+			So(op.Parameters, ShouldResemble, []*OpenAPIParameter{
+				{Name: "verbose", In: "query", Description: "more fields", Schema: &JSONSchema{Type: "boolean"}},
+				{Name: "id", In: "path", Required: true, Schema: &JSONSchema{Type: "integer"}},
+			})
+			So(op.RequestBody, ShouldBeNil)
+			So(op.Responses["200"].Description, ShouldEqual, "OK")
+			So(op.Responses["200"].Content["application/json"].Schema.Ref, ShouldEqual, "#/components/schemas/User")
+			So(op.Responses["404"].Description, ShouldEqual, "not found")
+			So(op.Responses["404"].Content["application/json"].Schema.Ref, ShouldEqual, "#/components/schemas/Error")
+		})
+
+		Convey("post", func() {
+			op := doc.Paths["/users"]["post"]
+			So(op.OperationID, ShouldEqual, "createUser")
+			So(op.Tags, ShouldResemble, []string{"users", "admin"})
+			So(op.RequestBody.Required, ShouldBeTrue)
+			So(op.RequestBody.Content["application/json"].Schema.Ref, ShouldEqual, "#/components/schemas/CreateUser")
+			So(op.Responses["201"].Content["application/json"].Schema.Ref, ShouldEqual, "#/components/schemas/User")
+		})
+
+		Convey("delete, put and list", func() {
+			op := doc.Paths["/users/{id}"]["delete"]
+			So(op.OperationID, ShouldEqual, "DeleteUser")
+			So(op.Parameters, ShouldResemble, []*OpenAPIParameter{
+				{Name: "id", In: "path", Description: "the user id", Required: true, Schema: &JSONSchema{Type: "string"}},
+			})
+			So(op.Responses["204"], ShouldResemble, &OpenAPIResponse{Description: "deleted"})
+
+			op = doc.Paths["/users/{id}"]["put"]
+			So(op.RequestBody.Content["application/json"].Schema.Items.Ref, ShouldEqual, "#/components/schemas/User")
+			So(op.Responses, ShouldContainKey, "default")
+
+			op = doc.Paths["/users"]["get"]
+			So(op.Responses["200"].Content["application/json"].Schema.Items.Ref, ShouldEqual, "#/components/schemas/User")
+		})
+
+		Convey("components", func() {
+			So(len(doc.Components.Schemas), ShouldEqual, 3)
+			So(doc.Components.Schemas["Error"].Description, ShouldEqual, "Error is the error response")
+			So(doc.Components.Schemas["CreateUser"].Required, ShouldResemble, []string{"name"})
+
+			data, err := json.Marshal(doc)
+			So(err, ShouldBeNil)
+			So(string(data), ShouldStartWith, `{"openapi":"3.1.0","info":{"title":"api","description":"Package api is the users api","version":"0.0.0"},"paths":{`)
+		})
+
+		Convey("errors", func() {
+			var p = &Package{Path: "example.com/api", Name: "api"}
+			f, err := ParseFile(openAPIBad, p)
+			So(err, ShouldBeNil)
+			p.Files = append(p.Files, f)
+			_, err = NewOpenAPI(p)
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldEndWith, "Bad: invalid type 1+2")
+
+			for msg, src := range openAPIPositional {
+				p := &Package{Path: "example.com/api", Name: "api"}
+				f, err := ParseFile(src, p)
+				So(err, ShouldBeNil)
+				p.Files = append(p.Files, f)
+				_, err = NewOpenAPI(p)
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldEndWith, msg)
+			}
+		})
+	})
+}
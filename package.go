@@ -2,11 +2,13 @@ package humanize
 
 import (
 	"fmt"
-	"go/ast"
+	"go/types"
 	"io/ioutil"
 	"os"
+	pathpkg "path"
 	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
 	"sync"
 )
@@ -16,8 +18,15 @@ type Package struct {
 	Files []*File
 	Path  string
 	Name  string
+	// Dir is the folder of the package, it is empty for the packages that are
+	// not loaded from the disk
+	Dir string
+	// Diagnostics is the list of non fatal problems found while loading the package
+	Diagnostics []Diagnostic
 
 	resolved bool
+	refs     map[interface{}][]*Reference // the uses in this package, by declaration
+	types    *types.Package               // only in ParsePackageWithTypes
 }
 
 var (
@@ -135,131 +144,16 @@ func translateToFullPath(path string) (string, error) {
 	return "", fmt.Errorf("%s is not found in GOROOT or GOPATH", path)
 }
 
-func checkTypeCast(p *Package, bi *Package, args []ast.Expr, name string) (Type, error) {
-	if len(args) != 1 {
-		return nil, fmt.Errorf("it can not be a typecast : %s", name)
-	}
-
-	t, err := bi.FindType(name)
-	if err == nil {
-		return t.Type, nil
-	}
-
-	// if the type is in this package, then simply pass an ident type
-	// not the actual type, since the actual type is the definition of
-	// the type
-	_, err = p.FindType(name)
-	if err == nil {
-		return &IdentType{Ident: name, srcBase: srcBase{pkg: p}}, nil
-	}
-
-	return nil, fmt.Errorf("can not find the call for %s", name)
-}
-
-func lateBind(p *Package) (res error) {
-	builtin, err := ParsePackage("builtin")
-	assertNil(err)
-
-	for f := range p.Files {
-		// Try to find variable with null type and change them to real type
-	thebigLoop:
-		for v := range p.Files[f].Variables {
-			if p.Files[f].Variables[v].caller != nil {
-				switch c := p.Files[f].Variables[v].caller.Fun.(type) {
-				case *ast.Ident:
-					name := nameFromIdent(c)
-					bl, err := builtin.FindFunction(name)
-					if err == nil {
-						p.Files[f].Variables[v].Type = bl.Type
-					} else {
-						var t Type
-						fn, err := p.FindFunction(name)
-						if err == nil {
-							if len(fn.Type.Results) <= p.Files[f].Variables[v].indx {
-								return fmt.Errorf("%d result is available but want the %d", len(fn.Type.Results), p.Files[f].Variables[v].indx)
-							}
-							t = fn.Type.Results[p.Files[f].Variables[v].indx].Type
-						} else {
-							t, err = checkTypeCast(p, builtin, p.Files[f].Variables[v].caller.Args, name)
-							if err != nil {
-								return err
-							}
-						}
-
-						p.Files[f].Variables[v].Type = t
-					}
-				case *ast.SelectorExpr:
-					var pkg string
-					switch c.X.(type) {
-					case *ast.Ident:
-						pkg = nameFromIdent(c.X.(*ast.Ident))
-					case *ast.CallExpr: // TODO : Don't know why, no time for check
-						continue thebigLoop
-					}
-
-					typ := nameFromIdent(c.Sel)
-					imprt, err := p.FindImport(pkg)
-					if err != nil {
-						// TODO : package currently is not capable of parsing build tags. so ignore this :/
-						continue thebigLoop
-					}
-					pkgDef, err := ParsePackage(imprt.Path)
-					if err != nil {
-						return err
-					}
-					var t Type
-					fn, err := pkgDef.FindFunction(typ)
-					if err == nil {
-						if len(fn.Type.Results) <= p.Files[f].Variables[v].indx {
-							return fmt.Errorf("%d result is available but want the %d", len(fn.Type.Results), p.Files[f].Variables[v].indx)
-						}
-						t = fn.Type.Results[p.Files[f].Variables[v].indx].Type
-					} else {
-						t, err = checkTypeCast(pkgDef, builtin, p.Files[f].Variables[v].caller.Args, typ)
-						if err != nil {
-							return err
-						}
-					}
-
-					foreignTyp := t
-					star := false
-					if sType, ok := foreignTyp.(*StarType); ok {
-						foreignTyp = sType.Target
-						star = true
-					}
-					switch ft := foreignTyp.(type) {
-					case *IdentType:
-						// this is a simple hack. if the type is begin with
-						// upper case, then its type on that package, else its a global type
-						name := ft.Ident
-						c := name[0]
-						if c >= 'A' && c <= 'Z' {
-							if star {
-								foreignTyp = &StarType{
-									ft.srcBase,
-									foreignTyp,
-								}
-							}
-							p.Files[f].Variables[v].Type = &SelectorType{
-								srcBase: srcBase{p, ""}, // TODO : source?
-								pkg:     getImport(imprt.Name, p.Files[f]),
-								Type:    foreignTyp,
-							}
-						} else {
-							if star {
-								foreignTyp = &StarType{
-									ft.srcBase,
-									foreignTyp,
-								}
-							}
-							p.Files[f].Variables[v].Type = foreignTyp
-						}
-
-					default:
-						// the type is foreign to that package too
-						p.Files[f].Variables[v].Type = ft
-					}
-				}
+// lateBind try to find the type of variables without explicit type, after all the
+// files are loaded
+func lateBind(p *Package) error {
+	findMethods(p)
+	et := newTyper(p, nil)
+	for _, f := range p.Files {
+		et = et.forFile(f)
+		for _, v := range f.Variables {
+			if err := et.bind(v); err != nil {
+				return err
 			}
 		}
 	}
@@ -324,17 +218,20 @@ func getGoFileContent(path, folder string, f os.FileInfo) (string, error) {
 	return string(data), nil
 }
 
-// ParsePackage is here for loading a single package and parse all files in it
-func ParsePackage(path string) (*Package, error) {
-	if p := getCache(path); p != nil {
-		return p, nil
-	}
-	var p = &Package{}
-	p.Path = path
+// parseFiles find the package folder and parse all the files in it
+func parseFiles(path string) (*Package, error) {
 	folder, err := translateToFullPath(path)
 	if err != nil {
 		return nil, err
 	}
+	return parseFolder(path, folder)
+}
+
+// parseFolder parse all the files in the folder, the path is the import path
+func parseFolder(path, folder string) (*Package, error) {
+	var p = &Package{}
+	p.Path = path
+	p.Dir = folder
 	gopath := strings.Split(os.Getenv("GOPATH"), ":")
 	tmp := folder
 bigLoop:
@@ -353,14 +250,14 @@ bigLoop:
 		tmp = filepath.Dir(tmp)
 	}
 
-	err = filepath.Walk(
+	err := filepath.Walk(
 		folder,
 		func(path string, f os.FileInfo, err error) error {
 			data, err := getGoFileContent(path, folder, f)
 			if err != nil || data == "" {
 				return err
 			}
-			fl, err := ParseFile(string(data), p)
+			fl, err := parseFile(path, string(data), p)
 			if err != nil {
 				return err
 			}
@@ -374,6 +271,91 @@ bigLoop:
 	if err != nil {
 		return nil, err
 	}
+	resolveEmbeds(p)
+	return p, nil
+}
+
+// ParsePackage is here for loading a single package and parse all files in it
+func ParsePackage(path string) (*Package, error) {
+	return loadPackage(path, func() (*Package, error) {
+		return parseFiles(path)
+	})
+}
+
+// ParseDir load the package in the folder, the import path is base on the GOPATH, or
+// the go.mod file if the folder is not in the GOPATH
+func ParseDir(dir string) (*Package, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	path, err := importPath(dir)
+	if err != nil {
+		return nil, err
+	}
+	return loadPackage(path, func() (*Package, error) {
+		return parseFolder(path, dir)
+	})
+}
+
+// importPath return the import path of the folder
+func importPath(dir string) (string, error) {
+	for _, gp := range strings.Split(os.Getenv("GOPATH"), ":") {
+		if gp == "" {
+			continue
+		}
+		rel, err := filepath.Rel(filepath.Join(gp, "src"), dir)
+		if err == nil && rel != "." && !strings.HasPrefix(rel, "..") {
+			return filepath.ToSlash(rel), nil
+		}
+	}
+	for mod := dir; ; mod = filepath.Dir(mod) {
+		data, err := ioutil.ReadFile(filepath.Join(mod, "go.mod"))
+		if err == nil {
+			name := modulePath(string(data))
+			if name == "" {
+				return "", fmt.Errorf("no module path in %s", filepath.Join(mod, "go.mod"))
+			}
+			rel, err := filepath.Rel(mod, dir)
+			if err != nil {
+				return "", err
+			}
+			return pathpkg.Join(name, filepath.ToSlash(rel)), nil
+		}
+		if mod == filepath.Dir(mod) {
+			break
+		}
+	}
+	return "", fmt.Errorf("%s is not in GOPATH or a module", dir)
+}
+
+// modulePath return the module path in the go.mod file
+func modulePath(mod string) string {
+	for _, line := range strings.Split(mod, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "module") {
+			continue
+		}
+		line = strings.TrimSpace(strings.TrimPrefix(line, "module"))
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		if s, err := strconv.Unquote(line); err == nil {
+			return s
+		}
+		return line
+	}
+	return ""
+}
+
+func loadPackage(path string, parse func() (*Package, error)) (*Package, error) {
+	if p := getCache(path); p != nil {
+		return p, nil
+	}
+	p, err := parse()
+	if err != nil {
+		return nil, err
+	}
 	setCache(path, p)
 	err = lateBind(p)
 	if err != nil {
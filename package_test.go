@@ -1,6 +1,8 @@
 package humanize
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	. "github.com/smartystreets/goconvey/convey"
@@ -231,4 +233,31 @@ func TestErrors(t *testing.T) {
 		err = lateBind(p)
 		So(err, ShouldNotBeNil)
 	})
+
+	Convey("parse dir", t, func() {
+		So(modulePath("// comment\nmodule example.com/mod // the module\n\ngo 1.20\n"), ShouldEqual, "example.com/mod")
+		So(modulePath("module \"example.com/quoted\"\n"), ShouldEqual, "example.com/quoted")
+		So(modulePath("go 1.20\n"), ShouldEqual, "")
+
+		dir := t.TempDir()
+		sub := filepath.Join(dir, "sub")
+		So(os.Mkdir(sub, 0755), ShouldBeNil)
+		So(os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/parsedir\n"), 0644), ShouldBeNil)
+		So(os.WriteFile(filepath.Join(sub, "a.go"), []byte("package sub\n\ntype A int\n"), 0644), ShouldBeNil)
+
+		path, err := importPath(sub)
+		So(err, ShouldBeNil)
+		So(path, ShouldEqual, "example.com/parsedir/sub")
+
+		p, err := ParseDir(sub)
+		So(err, ShouldBeNil)
+		So(p.Path, ShouldEqual, "example.com/parsedir/sub")
+		So(p.Name, ShouldEqual, "sub")
+		_, err = p.FindType("A")
+		So(err, ShouldBeNil)
+		So(getCache("example.com/parsedir/sub"), ShouldEqual, p)
+
+		_, err = importPath(string(filepath.Separator))
+		So(err, ShouldNotBeNil)
+	})
 }
@@ -0,0 +1,526 @@
+package humanize
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/printer"
+	"go/token"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Printer render the humanize model as gofmt-ed go source. the package names of the
+// types from the other packages are from the import set, so the generators can
+// collect the imports while they render the code
+type Printer struct {
+	Imports *ImportSet
+}
+
+// NewPrinter return a new printer, a nil import set means a new empty one
+func NewPrinter(imports *ImportSet) *Printer {
+	if imports == nil {
+		imports = NewImportSet()
+	}
+	return &Printer{Imports: imports}
+}
+
+// Sprint return the go source of the value. it can be a Type, *TypeName, *Function,
+// *Variable, []*Variable, *Constant, []*Constant or *File
+func (pr *Printer) Sprint(v interface{}) (string, error) {
+	var (
+		src string
+		err error
+	)
+	switch x := v.(type) {
+	case *File:
+		return pr.file(x)
+	case *TypeName:
+		src, err = pr.typeName(x, true)
+	case *Function:
+		src, err = pr.function(x)
+	case *Variable:
+		src, err = pr.variables([]*Variable{x})
+	case []*Variable:
+		src, err = pr.variables(x)
+	case *Constant:
+		src, err = pr.constant(x)
+	case []*Constant:
+		src, err = pr.constants(x)
+	case Type:
+		return pr.typ(x)
+	default:
+		return "", fmt.Errorf("can not print %T", v)
+	}
+	if err != nil {
+		return "", err
+	}
+	return formatDecl(src)
+}
+
+// Fprint write the go source of the value to the writer, see Sprint
+func (pr *Printer) Fprint(w io.Writer, v interface{}) error {
+	src, err := pr.Sprint(v)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, src)
+	return err
+}
+
+// formatDecl gofmt the declarations, they are not a valid file without the package
+func formatDecl(src string) (string, error) {
+	const header = "package p\n\n"
+	res, err := format.Source([]byte(header + src))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimPrefix(string(res), header), nil
+}
+
+// typ return the gofmt-ed source of a single type
+func (pr *Printer) typ(t Type) (string, error) {
+	const header = "type _ "
+	src, err := pr.typeSrc(t)
+	if err != nil {
+		return "", err
+	}
+	res, err := formatDecl(header + src + "\n")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(res, header), "\n"), nil
+}
+
+func (pr *Printer) node(n ast.Node) (string, error) {
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, token.NewFileSet(), n); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// typeSrc return the source of the type, the struct and interface types are with
+// the docs and the comments of the fields and the methods
+func (pr *Printer) typeSrc(t Type) (string, error) {
+	switch x := t.(type) {
+	case nil:
+		return "", fmt.Errorf("nil type")
+	case *StructType:
+		return pr.structSrc(x)
+	case *InterfaceType:
+		return pr.interfaceSrc(x)
+	}
+	e := ToAstExpr(t, pr.Imports)
+	if e == nil {
+		return "", fmt.Errorf("unsupported type %T", t)
+	}
+	return pr.node(e)
+}
+
+func docLines(buf *bytes.Buffer, docs Docs, directives []*Directive) {
+	for _, d := range docs {
+		buf.WriteString(d + "\n")
+	}
+	for _, d := range directives {
+		buf.WriteString("//" + d.Name)
+		if d.Text != "" {
+			buf.WriteString(" " + d.Text)
+		}
+		buf.WriteString("\n")
+	}
+}
+
+func trailing(buf *bytes.Buffer, comment Docs) {
+	if len(comment) > 0 {
+		buf.WriteString(" " + strings.Join(comment, " "))
+	}
+	buf.WriteString("\n")
+}
+
+func (pr *Printer) structSrc(st *StructType) (string, error) {
+	if len(st.Fields) == 0 && len(st.Embeds) == 0 {
+		return "struct{}", nil
+	}
+	buf := &bytes.Buffer{}
+	buf.WriteString("struct {\n")
+	for _, e := range st.Embeds {
+		src, err := pr.typeSrc(e.Type)
+		if err != nil {
+			return "", err
+		}
+		docLines(buf, e.Docs, nil)
+		buf.WriteString(src)
+		if e.Tags != "" {
+			buf.WriteString(" `" + string(e.Tags) + "`")
+		}
+		trailing(buf, e.Comment)
+	}
+	for _, f := range st.Fields {
+		src, err := pr.typeSrc(f.Type)
+		if err != nil {
+			return "", err
+		}
+		docLines(buf, f.Docs, nil)
+		buf.WriteString(f.Name + " " + src)
+		if f.Tags != "" {
+			buf.WriteString(" `" + string(f.Tags) + "`")
+		}
+		trailing(buf, f.Comment)
+	}
+	buf.WriteString("}")
+	return buf.String(), nil
+}
+
+func (pr *Printer) interfaceSrc(it *InterfaceType) (string, error) {
+	if len(it.Functions) == 0 && len(it.Embed) == 0 {
+		return "interface{}", nil
+	}
+	buf := &bytes.Buffer{}
+	buf.WriteString("interface {\n")
+	for _, e := range it.Embed {
+		src, err := pr.typeSrc(e)
+		if err != nil {
+			return "", err
+		}
+		buf.WriteString(src + "\n")
+	}
+	for _, fn := range it.Functions {
+		ft := ToAstExpr(fn.Type, pr.Imports)
+		if ft == nil {
+			return "", fmt.Errorf("method %s: unsupported type", fn.Name)
+		}
+		src, err := pr.node(ft)
+		if err != nil {
+			return "", err
+		}
+		docLines(buf, fn.Docs, nil)
+		buf.WriteString(fn.Name + strings.TrimPrefix(src, "func") + "\n")
+	}
+	buf.WriteString("}")
+	return buf.String(), nil
+}
+
+// typeName return the type declaration, and the methods if withMethods is true
+func (pr *Printer) typeName(tn *TypeName, withMethods bool) (string, error) {
+	if tn.generic {
+		return "", fmt.Errorf("type %s: generic types can not be printed", tn.Name)
+	}
+	src, err := pr.typeSrc(tn.Type)
+	if err != nil {
+		return "", err
+	}
+	buf := &bytes.Buffer{}
+	docLines(buf, tn.Docs, tn.Directives)
+	buf.WriteString("type " + tn.Name + " " + src)
+	trailing(buf, tn.Comment)
+	if !withMethods {
+		return buf.String(), nil
+	}
+	for _, fn := range append(tn.Methods, tn.StarMethods...) {
+		src, err := pr.function(fn)
+		if err != nil {
+			return "", err
+		}
+		buf.WriteString("\n" + src)
+	}
+	return buf.String(), nil
+}
+
+// function return the function declaration, the body is the original source, so the
+// package names in the body are not from the import set
+func (pr *Printer) function(fn *Function) (string, error) {
+	if fn.Type == nil {
+		return "", fmt.Errorf("function %s has no type", fn.Name)
+	}
+	if fn.generic() {
+		return "", fmt.Errorf("function %s: generic functions can not be printed", fn.Name)
+	}
+	ft, ok := ToAstExpr(fn.Type, pr.Imports).(*ast.FuncType)
+	if !ok {
+		return "", fmt.Errorf("function %s: unsupported type", fn.Name)
+	}
+	decl := &ast.FuncDecl{
+		Name: ast.NewIdent(removeReceiver(fn.Name)),
+		Type: ft,
+	}
+	if fn.Receiver != nil {
+		recv := &ast.Field{Type: ToAstExpr(fn.Receiver.Type, pr.Imports)}
+		if recv.Type == nil {
+			return "", fmt.Errorf("function %s: unsupported receiver type", fn.Name)
+		}
+		if fn.Receiver.Name != "" {
+			recv.Names = []*ast.Ident{ast.NewIdent(fn.Receiver.Name)}
+		}
+		decl.Recv = &ast.FieldList{List: []*ast.Field{recv}}
+	}
+	src, err := pr.node(decl)
+	if err != nil {
+		return "", err
+	}
+	buf := &bytes.Buffer{}
+	docLines(buf, fn.Docs, fn.Directives)
+	buf.WriteString(src)
+	if fn.decl != nil && fn.decl.Body != nil && fn.file != nil {
+		buf.WriteString(" " + getSource(fn.decl.Body, fn.file.src))
+	}
+	buf.WriteString("\n")
+	return buf.String(), nil
+}
+
+// siblings return the number of the variables with the same initializer, it is more
+// than one for the multi value initializers like var a, b = f()
+func siblings(v *Variable) int {
+	if v.expr == nil || v.file == nil {
+		return 1
+	}
+	n := 0
+	for _, other := range v.file.Variables {
+		if other.expr == v.expr {
+			n++
+		}
+	}
+	return n
+}
+
+// varSpec return a single spec, all the variables are from the same multi value
+// initializer, or there is only one variable
+func (pr *Printer) varSpec(vars []*Variable, complete bool) (string, error) {
+	v := vars[0]
+	buf := &bytes.Buffer{}
+	docLines(buf, v.SpecDocs, v.Directives)
+	names := make([]string, len(vars))
+	for i := range vars {
+		names[i] = vars[i].Name
+	}
+	buf.WriteString(strings.Join(names, ", "))
+	withInit := v.Initializer != "" && complete
+	if v.typeExpr != nil || !withInit {
+		src, err := pr.typeSrc(v.Type)
+		if err != nil {
+			return "", fmt.Errorf("variable %s: %w", v.Name, err)
+		}
+		buf.WriteString(" " + src)
+	}
+	if withInit {
+		buf.WriteString(" = " + v.Initializer)
+	}
+	var comment Docs
+	for i := range vars {
+		comment = append(comment, vars[i].Comment...)
+	}
+	trailing(buf, comment)
+	return buf.String(), nil
+}
+
+// variables return the var declarations, the variables from the same declaration are
+// in a group
+func (pr *Printer) variables(vars []*Variable) (string, error) {
+	buf := &bytes.Buffer{}
+	for i := 0; i < len(vars); {
+		j := i + 1
+		for j < len(vars) && vars[j].decl != nil && vars[j].decl == vars[i].decl {
+			j++
+		}
+		var specs []string
+		for k := i; k < j; {
+			n := siblings(vars[k])
+			l := k + 1
+			for n > 1 && l < j && vars[l].expr == vars[k].expr {
+				l++
+			}
+			// the initializer is only there if all the variables are here
+			src, err := pr.varSpec(vars[k:l], n == l-k)
+			if err != nil {
+				return "", err
+			}
+			specs = append(specs, src)
+			k = l
+		}
+		group(buf, "var", vars[i].GroupDocs, specs)
+		i = j
+	}
+	return buf.String(), nil
+}
+
+// group write the specs in a single declaration
+func group(buf *bytes.Buffer, tok string, docs Docs, specs []string) {
+	if buf.Len() > 0 {
+		buf.WriteString("\n")
+	}
+	docLines(buf, docs, nil)
+	if len(specs) == 1 {
+		buf.WriteString(tok + " " + specs[0])
+		return
+	}
+	buf.WriteString(tok + " (\n")
+	for _, s := range specs {
+		buf.WriteString(s)
+	}
+	buf.WriteString(")\n")
+}
+
+// constSpec return the constants of a single spec, like a, b = iota, iota * 2
+func (pr *Printer) constSpec(cs []*Constant) (string, error) {
+	c := cs[0]
+	buf := &bytes.Buffer{}
+	docLines(buf, c.SpecDocs, nil)
+	var names, values []string
+	var comment Docs
+	for i := range cs {
+		names = append(names, cs[i].Name)
+		if cs[i].Initializer != "" {
+			values = append(values, cs[i].Initializer)
+		}
+		comment = append(comment, cs[i].Comment...)
+	}
+	buf.WriteString(strings.Join(names, ", "))
+	// no value means the implicit repetition of the last one
+	if len(values) > 0 {
+		if c.typeExpr != nil {
+			src, err := pr.typeSrc(c.Type)
+			if err != nil {
+				return "", fmt.Errorf("constant %s: %w", c.Name, err)
+			}
+			buf.WriteString(" " + src)
+		}
+		buf.WriteString(" = " + strings.Join(values, ", "))
+	}
+	trailing(buf, comment)
+	return buf.String(), nil
+}
+
+// constants return the const declarations, the groups are the same as the source
+// since the iota and the implicit repetition depend on them
+func (pr *Printer) constants(cs []*Constant) (string, error) {
+	buf := &bytes.Buffer{}
+	for i := 0; i < len(cs); {
+		j := i + 1
+		for j < len(cs) && cs[j].decl != nil && cs[j].decl == cs[i].decl {
+			j++
+		}
+		var specs []string
+		for k := i; k < j; {
+			l := k + 1
+			for l < j && cs[l].iota == cs[k].iota {
+				l++
+			}
+			src, err := pr.constSpec(cs[k:l])
+			if err != nil {
+				return "", err
+			}
+			specs = append(specs, src)
+			k = l
+		}
+		group(buf, "const", cs[i].GroupDocs, specs)
+		i = j
+	}
+	return buf.String(), nil
+}
+
+// constant return a single constant declaration. if the value depends on the group,
+// (iota or the implicit repetition) the value is the computed one
+func (pr *Printer) constant(c *Constant) (string, error) {
+	if c.iota == 0 && c.Initializer != "" {
+		return pr.constants([]*Constant{c})
+	}
+	v, err := c.Evaluate()
+	if err != nil {
+		return "", err
+	}
+	cp := *c
+	cp.Initializer = v.ExactString()
+	return pr.constants([]*Constant{&cp})
+}
+
+// declPos return the position of the declaration, no position if it is not parsed
+func declPos(d *ast.GenDecl) token.Pos {
+	if d == nil {
+		return token.NoPos
+	}
+	return d.Pos()
+}
+
+// file return the entire file, the imports of the file are added to the import set
+// first, so the package names are the same as the source. the loose comments are not
+// in the result
+func (pr *Printer) file(f *File) (string, error) {
+	buf := &bytes.Buffer{}
+	for _, d := range f.Directives {
+		if d.Constraint != nil {
+			buf.WriteString("//" + d.Name + " " + d.Text + "\n")
+		}
+	}
+	if buf.Len() > 0 {
+		buf.WriteString("\n")
+	}
+	docLines(buf, f.Docs, nil)
+	buf.WriteString("package " + f.PackageName + "\n\n")
+
+	for _, imp := range f.Imports {
+		pr.Imports.Alias(imp.Path, imp.Name)
+	}
+
+	// the declarations are in the source order, the json files have no position so
+	// they are in the order of the kinds
+	type part struct {
+		pos token.Pos
+		src func() (string, error)
+	}
+	var parts []part
+	for i := 0; i < len(f.Constants); {
+		j := i + 1
+		for j < len(f.Constants) && f.Constants[j].decl != nil && f.Constants[j].decl == f.Constants[i].decl {
+			j++
+		}
+		cs := f.Constants[i:j]
+		parts = append(parts, part{pos: declPos(cs[0].decl), src: func() (string, error) { return pr.constants(cs) }})
+		i = j
+	}
+	for i := 0; i < len(f.Variables); {
+		j := i + 1
+		for j < len(f.Variables) && f.Variables[j].decl != nil && f.Variables[j].decl == f.Variables[i].decl {
+			j++
+		}
+		vs := f.Variables[i:j]
+		parts = append(parts, part{pos: declPos(vs[0].decl), src: func() (string, error) { return pr.variables(vs) }})
+		i = j
+	}
+	for _, tn := range f.Types {
+		tn := tn
+		parts = append(parts, part{pos: tn.pos, src: func() (string, error) { return pr.typeName(tn, false) }})
+	}
+	for _, fn := range f.Functions {
+		fn := fn
+		var pos token.Pos
+		if fn.decl != nil {
+			pos = fn.decl.Pos()
+		}
+		parts = append(parts, part{pos: pos, src: func() (string, error) { return pr.function(fn) }})
+	}
+	sort.SliceStable(parts, func(i, j int) bool { return parts[i].pos < parts[j].pos })
+
+	body := &bytes.Buffer{}
+	for _, d := range parts {
+		src, err := d.src()
+		if err != nil {
+			return "", err
+		}
+		if src != "" {
+			body.WriteString(src + "\n")
+		}
+	}
+
+	if imports := pr.Imports.Source(); imports != "" {
+		buf.WriteString(imports + "\n")
+	}
+	buf.WriteString(body.String())
+
+	res, err := format.Source(buf.Bytes())
+	if err != nil {
+		return "", err
+	}
+	return string(res), nil
+}
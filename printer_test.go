@@ -0,0 +1,231 @@
+package humanize
+
+import (
+	"bytes"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+var printSrc = `// Package test is for the printer
+package test
+
+import (
+	"io"
+
+	"github.com/goraz/humanize/fixture"
+)
+
+// the colors
+const (
+	Red Color = iota // the first one
+	Green
+	Blue
+
+	a, b = iota, iota * 2
+)
+
+const Name = "test"
+
+var (
+	// w is a writer
+	w io.Writer
+	x, y = pair()
+	s    = []string{"a"}
+)
+
+// Color is a color
+type Color int
+
+// Shape is the interface
+type Shape interface {
+	io.Reader
+	// Area return the area
+	Area() float64
+}
+
+type Box struct {
+	Shape
+	// Name of the box
+	Name   string ` + "`json:\"name\"`" + ` // the name
+	T     *fixture.T1
+	Inner struct {
+		X, Y int
+	}
+}
+
+func pair() (int, string) {
+	return 1, "a"
+}
+
+// String return the name
+func (c Color) String() string {
+	switch c {
+	case Red:
+		return "red"
+	}
+	return ""
+}
+
+func Join(sep string, parts ...string) (res string, err error) {
+	return "", nil
+}
+`
+
+var printOrder = `package test
+
+func A() {}
+
+type T int
+
+const (
+	X = iota
+	Y
+)
+
+var v = 1
+
+const Z = 2
+`
+
+var printGeneric = `package test
+
+type List[T any] struct {
+	Items []T
+}
+
+func (l *List[T]) Len() int { return len(l.Items) }
+
+func Use(l List[int]) {}
+
+func Map[T any](v T) T { return v }
+`
+
+func TestPrinter(t *testing.T) {
+	Convey("Printer test", t, func() {
+		var p = &Package{Path: "example.com/test", Name: "test"}
+		f, err := ParseFile(printSrc, p)
+		So(err, ShouldBeNil)
+		p.Files = append(p.Files, f)
+		So(lateBind(p), ShouldBeNil)
+
+		pr := NewPrinter(nil)
+
+		Convey("types", func() {
+			tn, err := p.FindType("Box")
+			So(err, ShouldBeNil)
+			src, err := pr.Sprint(tn.Type.(*StructType).Fields[1].Type)
+			So(err, ShouldBeNil)
+			So(src, ShouldEqual, "*fixture.T1")
+			So(pr.Imports.names, ShouldResemble, map[string]string{"github.com/goraz/humanize/fixture": "fixture"})
+
+			src, err = pr.Sprint(tn)
+			So(err, ShouldBeNil)
+			So(src, ShouldEqual, "type Box struct {\n\tShape\n\t// Name of the box\n\tName  string `json:\"name\"` // the name\n\tT     *fixture.T1\n\tInner struct {\n\t\tX int\n\t\tY int\n\t}\n}\n")
+
+			tn, err = p.FindType("Color")
+			So(err, ShouldBeNil)
+			src, err = pr.Sprint(tn)
+			So(err, ShouldBeNil)
+			So(src, ShouldEqual, "// Color is a color\ntype Color int\n\n// String return the name\nfunc (c Color) String() string {\n\tswitch c {\n\tcase Red:\n\t\treturn \"red\"\n\t}\n\treturn \"\"\n}\n")
+
+			tn, err = p.FindType("Shape")
+			So(err, ShouldBeNil)
+			src, err = pr.Sprint(tn.Type)
+			So(err, ShouldBeNil)
+			So(src, ShouldEqual, "interface {\n\tio.Reader\n\t// Area return the area\n\tArea() float64\n}")
+		})
+
+		Convey("functions", func() {
+			fn, err := p.FindFunction("Join")
+			So(err, ShouldBeNil)
+			buf := &bytes.Buffer{}
+			So(pr.Fprint(buf, fn), ShouldBeNil)
+			So(buf.String(), ShouldEqual, "func Join(sep string, parts ...string) (res string, err error) {\n\treturn \"\", nil\n}\n")
+
+			fn.Type.Parameters[1].Name = "rest"
+			src, err := pr.Sprint(fn.Type)
+			So(err, ShouldBeNil)
+			So(src, ShouldEqual, "func(sep string, rest ...string) (res string, err error)")
+		})
+
+		Convey("constants", func() {
+			src, err := pr.Sprint(f.Constants)
+			So(err, ShouldBeNil)
+			So(src, ShouldEqual, "// the colors\nconst (\n\tRed Color = iota // the first one\n\tGreen\n\tBlue\n\ta, b = iota, iota * 2\n)\n\nconst Name = \"test\"\n")
+
+			c, err := p.FindConstant("Blue")
+			So(err, ShouldBeNil)
+			src, err = pr.Sprint(c)
+			So(err, ShouldBeNil)
+			So(src, ShouldEqual, "// the colors\nconst Blue Color = 2\n")
+
+			c, err = p.FindConstant("b")
+			So(err, ShouldBeNil)
+			src, err = pr.Sprint(c)
+			So(err, ShouldBeNil)
+			So(src, ShouldEqual, "// the colors\nconst b = 6\n")
+		})
+
+		Convey("variables", func() {
+			src, err := pr.Sprint(f.Variables)
+			So(err, ShouldBeNil)
+			So(src, ShouldEqual, "var (\n\t// w is a writer\n\tw    io.Writer\n\tx, y = pair()\n\ts    = []string{\"a\"}\n)\n")
+
+			v, err := p.FindVariable("y")
+			So(err, ShouldBeNil)
+			src, err = pr.Sprint(v)
+			So(err, ShouldBeNil)
+			So(src, ShouldEqual, "var y string\n")
+		})
+
+		Convey("file", func() {
+			src, err := NewPrinter(nil).Sprint(f)
+			So(err, ShouldBeNil)
+			So(src, ShouldStartWith, "// Package test is for the printer\npackage test\n\nimport (\n\t\"io\"\n\n\t\"github.com/goraz/humanize/fixture\"\n)\n")
+			So(src, ShouldContainSubstring, "\nfunc (c Color) String() string {\n")
+			_, err = ParseFile(src, &Package{})
+			So(err, ShouldBeNil)
+		})
+
+		Convey("file order", func() {
+			f, err := ParseFile(printOrder, &Package{})
+			So(err, ShouldBeNil)
+			src, err := NewPrinter(nil).Sprint(f)
+			So(err, ShouldBeNil)
+			So(src, ShouldEqual, printOrder)
+		})
+
+		Convey("errors", func() {
+			_, err := pr.Sprint(42)
+			So(err, ShouldNotBeNil)
+			_, err = pr.Sprint(&Variable{Name: "x"})
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("generics", func() {
+			var p = &Package{Path: "example.com/test", Name: "test"}
+			f, err := ParseFile(printGeneric, p)
+			So(err, ShouldBeNil)
+			p.Files = append(p.Files, f)
+			So(lateBind(p), ShouldBeNil)
+
+			_, err = pr.Sprint(f)
+			So(err, ShouldNotBeNil)
+			tn, err := p.FindType("List")
+			So(err, ShouldBeNil)
+			_, err = pr.Sprint(tn)
+			So(err.Error(), ShouldEqual, "type List: generic types can not be printed")
+			fn, err := p.FindFunction("Use")
+			So(err, ShouldBeNil)
+			_, err = pr.Sprint(fn)
+			So(err.Error(), ShouldEqual, "function Use: unsupported type")
+			fn, err = p.FindFunction("Map")
+			So(err, ShouldBeNil)
+			_, err = pr.Sprint(fn)
+			So(err.Error(), ShouldEqual, "function Map: generic functions can not be printed")
+			_, err = pr.Sprint(tn.StarMethods[0])
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
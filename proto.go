@@ -0,0 +1,442 @@
+package humanize
+
+import (
+	"bytes"
+	"fmt"
+	"go/constant"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Proto is a proto3 file, created from the go types
+type Proto struct {
+	Package   string
+	GoPackage string
+	// Imports is the imports of the well-known types
+	Imports  []string
+	Messages []*ProtoMessage
+	Enums    []*ProtoEnum
+	// Diagnostics is the go constructs that can not be mapped, like channels, the
+	// fields are not in the messages
+	Diagnostics []Diagnostic
+}
+
+// ProtoMessage is a message, from a struct
+type ProtoMessage struct {
+	Name   string
+	Docs   Docs
+	Fields []*ProtoField
+}
+
+// ProtoField is a single field of a message
+type ProtoField struct {
+	Name     string
+	Type     string // for maps it is map<K, V>
+	Number   int
+	Repeated bool
+	Optional bool
+	Docs     Docs
+}
+
+// ProtoEnum is an enum, from the constants of a type
+type ProtoEnum struct {
+	Name   string
+	Docs   Docs
+	Values []*ProtoEnumValue
+	// Alias is true if there are values with the same number
+	Alias bool
+}
+
+// ProtoEnumValue is a single value of the enum
+type ProtoEnumValue struct {
+	Name   string
+	Number int64
+}
+
+var protoScalars = map[string]string{
+	"bool": "bool", "string": "string",
+	"int": "int64", "int8": "int32", "int16": "int32", "int32": "int32", "int64": "int64", "rune": "int32",
+	"uint": "uint64", "uint8": "uint32", "uint16": "uint32", "uint32": "uint32", "uint64": "uint64", "byte": "uint32",
+	"float32": "float", "float64": "double",
+}
+
+var protoWellKnown = map[string][2]string{
+	"time.Time":     {"google.protobuf.Timestamp", "google/protobuf/timestamp.proto"},
+	"time.Duration": {"google.protobuf.Duration", "google/protobuf/duration.proto"},
+}
+
+// protoBuilder create the messages and the enums, the named types that are used in
+// the fields are added too
+type protoBuilder struct {
+	res   *Proto
+	names map[*TypeName]string
+	// the current field, for the diagnostics
+	field string
+}
+
+// NewProto create the proto3 file for the types in the package, the types should be
+// structs or enums. the field numbers are from the proto:"n" tag or the @Proto n
+// annotation, the other fields get the next free number
+func NewProto(p *Package, names ...string) (*Proto, error) {
+	pb := &protoBuilder{
+		res: &Proto{
+			Package:   p.Name,
+			GoPackage: p.Path,
+		},
+		names: make(map[*TypeName]string),
+	}
+	for _, name := range names {
+		tn, err := p.FindType(name)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := tn.Type.(*StructType); !ok {
+			if _, err := p.FindEnum(name); err != nil {
+				return nil, fmt.Errorf("type %s is not a struct or an enum", name)
+			}
+		}
+		if _, err := pb.named(tn, p); err != nil {
+			return nil, err
+		}
+	}
+	sort.Strings(pb.res.Imports)
+	return pb.res, nil
+}
+
+func (pb *protoBuilder) diagnostic(format string, args ...interface{}) {
+	pb.res.Diagnostics = append(pb.res.Diagnostics, Diagnostic{Message: pb.field + ": " + fmt.Sprintf(format, args...)})
+}
+
+func (pb *protoBuilder) addImport(imp string) {
+	for _, i := range pb.res.Imports {
+		if i == imp {
+			return
+		}
+	}
+	pb.res.Imports = append(pb.res.Imports, imp)
+}
+
+// named return the proto type of the named type, the struct is a message, the enum is
+// an enum and the others are the proto type of the underlying type
+func (pb *protoBuilder) named(tn *TypeName, p *Package) (string, error) {
+	if name, ok := pb.names[tn]; ok {
+		return name, nil
+	}
+	if e, err := p.FindEnum(tn.Name); err == nil {
+		pb.names[tn] = tn.Name
+		pb.enum(e)
+		return tn.Name, nil
+	}
+	st, ok := tn.Type.(*StructType)
+	if !ok {
+		return pb.typ(tn.Type, p)
+	}
+	for _, m := range pb.res.Messages {
+		if m.Name == tn.Name {
+			return "", fmt.Errorf("there is more than one message with the name %s", tn.Name)
+		}
+	}
+	pb.names[tn] = tn.Name
+	m := &ProtoMessage{Name: tn.Name, Docs: tn.Docs}
+	pb.res.Messages = append(pb.res.Messages, m)
+	return tn.Name, pb.message(m, st, p)
+}
+
+// fieldNumber return the number from the proto tag or the annotation, zero if there
+// is none
+func fieldNumber(wf *wireField) (int, error) {
+	num := wf.Tags.Get("proto")
+	if num == "" && wf.Field != nil {
+		if a, ok := wf.Field.Annotations.Find("Proto"); ok && len(a.Args) > 0 {
+			num = a.Args[0]
+		}
+	}
+	if num == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(num)
+	if err != nil || n < 1 || n > 1<<29-1 || (n >= 19000 && n <= 19999) {
+		return 0, fmt.Errorf("invalid field number %s", num)
+	}
+	return n, nil
+}
+
+func (pb *protoBuilder) message(m *ProtoMessage, st *StructType, p *Package) error {
+	fields, err := structFields(st, p, "")
+	if err != nil {
+		return err
+	}
+	var (
+		used    = make(map[int]bool)
+		numbers = make([]int, len(fields))
+		skip    = make([]bool, len(fields))
+	)
+	for i, wf := range fields {
+		if wf.Tags.Get("proto") == "-" {
+			skip[i] = true
+			continue
+		}
+		pb.field = m.Name + "." + wf.Name
+		n, err := fieldNumber(wf)
+		if err == nil && n > 0 && used[n] {
+			err = fmt.Errorf("field number %d is used more than once", n)
+		}
+		if err != nil {
+			pb.diagnostic("%s", err)
+			skip[i] = true
+			continue
+		}
+		used[n] = true
+		numbers[i] = n
+	}
+	next := 1
+	for i, wf := range fields {
+		if skip[i] {
+			continue
+		}
+		pb.field = m.Name + "." + wf.Name
+		f := &ProtoField{Name: snakeCase(wf.Name), Number: numbers[i], Docs: wf.Docs}
+		if !pb.fieldType(f, wf.Type, wf.Package) {
+			continue
+		}
+		if f.Number == 0 {
+			for used[next] || (next >= 19000 && next <= 19999) {
+				next++
+			}
+			f.Number = next
+			used[next] = true
+		}
+		m.Fields = append(m.Fields, f)
+	}
+	return nil
+}
+
+// fieldType set the type of the field, it is false if the type can not be mapped
+func (pb *protoBuilder) fieldType(f *ProtoField, t Type, p *Package) bool {
+	if s, ok := t.(*StarType); ok {
+		f.Optional = true
+		t = s.Target
+	}
+	t, p = namedCollection(t, p)
+	if x, ok := t.(*EllipsisType); ok {
+		t = x.ArrayType
+	}
+	if a, ok := t.(*ArrayType); ok && !isBytes(a) {
+		f.Optional = false
+		f.Repeated = true
+		t = a.Type
+		if s, ok := t.(*StarType); ok {
+			t = s.Target
+		}
+		if a, ok := t.(*ArrayType); ok && !isBytes(a) {
+			pb.diagnostic("nested repeated field %s can not be mapped", goSource(a))
+			return false
+		}
+	}
+	if mt, ok := t.(*MapType); ok {
+		if f.Repeated {
+			pb.diagnostic("repeated map %s can not be mapped", goSource(mt))
+			return false
+		}
+		f.Optional = false
+		key, ok := pb.scalar(mt.Key, p)
+		if !ok || key == "float" || key == "double" || key == "bytes" {
+			pb.diagnostic("map key %s can not be mapped", goSource(mt.Key))
+			return false
+		}
+		value := mt.Value
+		if s, ok := value.(*StarType); ok {
+			value = s.Target
+		}
+		if _, ok := value.(*MapType); ok {
+			pb.diagnostic("map value %s can not be mapped", goSource(value))
+			return false
+		}
+		if a, ok := value.(*ArrayType); ok && !isBytes(a) {
+			pb.diagnostic("map value %s can not be mapped", goSource(value))
+			return false
+		}
+		v, err := pb.typ(value, p)
+		if err != nil {
+			pb.diagnostic("%s", err)
+			return false
+		}
+		f.Type = "map<" + key + ", " + v + ">"
+		return true
+	}
+	typ, err := pb.typ(t, p)
+	if err != nil {
+		pb.diagnostic("%s", err)
+		return false
+	}
+	f.Type = typ
+	return true
+}
+
+// goSource return the go source of the type, for the diagnostics
+func goSource(t Type) string {
+	if src, err := NewPrinter(nil).Sprint(t); err == nil {
+		return src
+	}
+	return t.GetDefinition()
+}
+
+// isBytes return true for the []byte, it is a string in encoding/json, base64 encoded
+func isBytes(a *ArrayType) bool {
+	id, ok := a.Type.(*IdentType)
+	return ok && a.Slice && (id.Ident == "byte" || id.Ident == "uint8")
+}
+
+// scalar return the proto scalar of the map key, the named types are resolved to
+// the underlying type
+func (pb *protoBuilder) scalar(t Type, p *Package) (string, bool) {
+	et := newTyper(p, nil)
+	if id, ok := et.underlying(t).(*IdentType); ok {
+		s, ok := protoScalars[id.Ident]
+		return s, ok
+	}
+	return "", false
+}
+
+// typ return the proto type of a single value
+func (pb *protoBuilder) typ(t Type, p *Package) (string, error) {
+	switch x := t.(type) {
+	case *IdentType:
+		if s, ok := protoScalars[x.Ident]; ok {
+			return s, nil
+		}
+		if predeclared[x.Ident] {
+			return "", fmt.Errorf("%s can not be mapped", x.Ident)
+		}
+	case *SelectorType:
+		if x.pkg != nil {
+			if wk, ok := protoWellKnown[x.pkg.Path+"."+x.Type.GetDefinition()]; ok {
+				pb.addImport(wk[1])
+				return wk[0], nil
+			}
+		}
+	case *ArrayType:
+		if isBytes(x) {
+			return "bytes", nil
+		}
+		return "", fmt.Errorf("repeated %s can not be mapped here", goSource(x))
+	case *StarType:
+		return pb.typ(x.Target, p)
+	case *StructType:
+		return "", fmt.Errorf("anonymous struct can not be mapped")
+	default:
+		// interface, chan, func and the others
+		return "", fmt.Errorf("%s can not be mapped", goSource(t))
+	}
+	tn, tp := newTyper(p, nil).typeName(t)
+	if tn == nil {
+		return "", fmt.Errorf("type %s not found", goSource(t))
+	}
+	return pb.named(tn, tp)
+}
+
+// enumValueName return the value name with the enum prefix, like COLOR_RED
+func enumValueName(enum, name string) string {
+	if n := strings.TrimPrefix(name, enum); n != "" {
+		name = n
+	}
+	return strings.ToUpper(snakeCase(enum) + "_" + snakeCase(name))
+}
+
+// enum add the proto enum, the zero value is the first one. if there is no zero
+// value there is a TYPE_UNSPECIFIED. the values of the non-integer enums are the
+// index of the constant
+func (pb *protoBuilder) enum(e *Enum) {
+	pe := &ProtoEnum{Name: e.Type.Name, Docs: e.Type.Docs}
+	var (
+		zero  *ProtoEnumValue
+		seen  = make(map[int64]bool)
+		index int64
+	)
+	for _, v := range e.Values {
+		if v.Value == nil || v.Constant.Name == "_" {
+			continue
+		}
+		index++
+		n := index
+		if v.Value.Kind() == constant.Int {
+			var ok bool
+			if n, ok = constant.Int64Val(v.Value); !ok || n < -1<<31 || n > 1<<31-1 {
+				pb.field = e.Type.Name + "." + v.Constant.Name
+				pb.diagnostic("enum value %s is out of range", v.Value)
+				continue
+			}
+		}
+		ev := &ProtoEnumValue{Name: enumValueName(e.Type.Name, v.Constant.Name), Number: n}
+		if seen[n] {
+			pe.Alias = true
+		}
+		seen[n] = true
+		if n == 0 && zero == nil {
+			zero = ev
+			continue
+		}
+		pe.Values = append(pe.Values, ev)
+	}
+	if zero == nil {
+		zero = &ProtoEnumValue{Name: enumValueName(e.Type.Name, "Unspecified")}
+	}
+	pe.Values = append([]*ProtoEnumValue{zero}, pe.Values...)
+	pb.res.Enums = append(pb.res.Enums, pe)
+}
+
+func protoDocs(buf *bytes.Buffer, indent string, d Docs) {
+	text := description(d)
+	if text == "" {
+		return
+	}
+	for _, l := range strings.Split(text, "\n") {
+		buf.WriteString(strings.TrimRight(indent+"// "+l, " ") + "\n")
+	}
+}
+
+// String return the source of the proto file
+func (pr *Proto) String() string {
+	buf := &bytes.Buffer{}
+	buf.WriteString("syntax = \"proto3\";\n\n")
+	fmt.Fprintf(buf, "package %s;\n\n", pr.Package)
+	for _, imp := range pr.Imports {
+		fmt.Fprintf(buf, "import %q;\n", imp)
+	}
+	if len(pr.Imports) > 0 {
+		buf.WriteString("\n")
+	}
+	if pr.GoPackage != "" {
+		fmt.Fprintf(buf, "option go_package = %q;\n", pr.GoPackage)
+	}
+	for _, m := range pr.Messages {
+		buf.WriteString("\n")
+		protoDocs(buf, "", m.Docs)
+		fmt.Fprintf(buf, "message %s {\n", m.Name)
+		for _, f := range m.Fields {
+			protoDocs(buf, "  ", f.Docs)
+			buf.WriteString("  ")
+			if f.Repeated {
+				buf.WriteString("repeated ")
+			} else if f.Optional {
+				buf.WriteString("optional ")
+			}
+			fmt.Fprintf(buf, "%s %s = %d;\n", f.Type, f.Name, f.Number)
+		}
+		buf.WriteString("}\n")
+	}
+	for _, e := range pr.Enums {
+		buf.WriteString("\n")
+		protoDocs(buf, "", e.Docs)
+		fmt.Fprintf(buf, "enum %s {\n", e.Name)
+		if e.Alias {
+			buf.WriteString("  option allow_alias = true;\n")
+		}
+		for _, v := range e.Values {
+			fmt.Fprintf(buf, "  %s = %d;\n", v.Name, v.Number)
+		}
+		buf.WriteString("}\n")
+	}
+	return buf.String()
+}
@@ -0,0 +1,145 @@
+package humanize
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+var protoSrc = `package test
+
+import "time"
+
+// Color is a color
+type Color int
+
+const (
+	Red Color = iota + 1
+	Green
+	Crimson Color = 1
+)
+
+type Level int
+
+const (
+	LevelLow Level = iota
+	LevelHigh
+)
+
+type IDs []string
+
+type Names IDs
+
+// User is a user
+type User struct {
+	Base
+	// Name of the user
+	Name    string ` + "`proto:\"5\"`" + `
+	// @Proto 2
+	Age     *int
+	Color   Color
+	Levels  []Level
+	Friends []*User
+	Data    []byte
+	Tags    IDs
+	Meta    map[string]*Item
+	Born    time.Time
+	TTL     time.Duration
+	Ch      chan int
+	Fn      func()
+	Any     interface{}
+	Grid    [][]int
+	Bad     map[float64]int
+	Skip    string ` + "`proto:\"-\"`" + `
+	Dup     string ` + "`proto:\"5\"`" + `
+	Aliases Names
+	private int
+}
+
+type Base struct {
+	ID uint64
+}
+
+type Item struct {
+	Price float32
+}
+`
+
+func TestProto(t *testing.T) {
+	Convey("Proto test", t, func() {
+		var p = &Package{Path: "example.com/test", Name: "test"}
+		f, err := ParseFile(protoSrc, p)
+		So(err, ShouldBeNil)
+		p.Files = append(p.Files, f)
+		So(lateBind(p), ShouldBeNil)
+
+		pr, err := NewProto(p, "User", "Level")
+		So(err, ShouldBeNil)
+		So(pr.Imports, ShouldResemble, []string{"google/protobuf/duration.proto", "google/protobuf/timestamp.proto"})
+
+		var diags []string
+		for _, d := range pr.Diagnostics {
+			diags = append(diags, d.String())
+		}
+		So(diags, ShouldResemble, []string{
+			"User.Dup: field number 5 is used more than once",
+			"User.Ch: chan int can not be mapped",
+			"User.Fn: func() can not be mapped",
+			"User.Any: interface{} can not be mapped",
+			"User.Grid: nested repeated field []int can not be mapped",
+			"User.Bad: map key float64 can not be mapped",
+		})
+
+		So(pr.String(), ShouldEqual, `syntax = "proto3";
+
+package test;
+
+import "google/protobuf/duration.proto";
+import "google/protobuf/timestamp.proto";
+
+option go_package = "example.com/test";
+
+// User is a user
+message User {
+  // Name of the user
+  string name = 5;
+  optional int64 age = 2;
+  Color color = 1;
+  repeated Level levels = 3;
+  repeated User friends = 4;
+  bytes data = 6;
+  repeated string tags = 7;
+  map<string, Item> meta = 8;
+  google.protobuf.Timestamp born = 9;
+  google.protobuf.Duration ttl = 10;
+  repeated string aliases = 11;
+  uint64 id = 12;
+}
+
+message Item {
+  float price = 1;
+}
+
+// Color is a color
+enum Color {
+  option allow_alias = true;
+  COLOR_UNSPECIFIED = 0;
+  COLOR_RED = 1;
+  COLOR_GREEN = 2;
+  COLOR_CRIMSON = 1;
+}
+
+enum Level {
+  LEVEL_LOW = 0;
+  LEVEL_HIGH = 1;
+}
+`)
+
+		Convey("errors", func() {
+			_, err := NewProto(p, "IDs")
+			So(err, ShouldNotBeNil)
+			_, err = NewProto(p, "Missing")
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
@@ -0,0 +1,392 @@
+package humanize
+
+import (
+	"go/ast"
+	"go/token"
+	"sort"
+)
+
+// ReferenceKind is the kind of a use of a declaration
+type ReferenceKind int
+
+const (
+	// TypeReference is the use of a type in a type expression or a conversion
+	TypeReference ReferenceKind = iota + 1
+	// CallReference is a call to a function or method
+	CallReference
+	// ValueReference is the use of a variable, constant or a function value
+	ValueReference
+	// CompositeReference is a type in a composite literal, like T{}
+	CompositeReference
+	// EmbedReference is an embedded type in a struct or interface
+	EmbedReference
+)
+
+// String return the name of the kind
+func (k ReferenceKind) String() string {
+	switch k {
+	case TypeReference:
+		return "type"
+	case CallReference:
+		return "call"
+	case ValueReference:
+		return "value"
+	case CompositeReference:
+		return "composite"
+	case EmbedReference:
+		return "embed"
+	}
+	return "unknown"
+}
+
+// Reference is a single use of a declaration
+type Reference struct {
+	Kind    ReferenceKind
+	Pos     token.Position
+	File    *File
+	Package *Package
+	// Enclosing is the name of the package level declaration that the use is in, like
+	// Type.Method for methods. it is empty for the file level uses
+	Enclosing string
+}
+
+// refWalker find the uses in a single package level declaration
+type refWalker struct {
+	pkg       *Package
+	file      *File
+	enclosing string
+	// locals is the local names in the function and the function literals, the scope
+	// is flat
+	locals map[string]bool
+}
+
+func (rw *refWalker) add(decl interface{}, kind ReferenceKind, pos token.Pos) {
+	if rw.pkg.refs == nil {
+		rw.pkg.refs = make(map[interface{}][]*Reference)
+	}
+	rw.pkg.refs[decl] = append(rw.pkg.refs[decl], &Reference{
+		Kind:      kind,
+		Pos:       rw.file.position(pos),
+		File:      rw.file,
+		Package:   rw.pkg,
+		Enclosing: rw.enclosing,
+	})
+}
+
+// lookup find the package level declaration with the name, and add the reference. the
+// ctx is the Call, Composite or Embed kind, zero means the kind is base on the declaration
+func (rw *refWalker) lookup(p *Package, name string, ctx ReferenceKind, pos token.Pos) {
+	if tn, err := p.FindType(name); err == nil {
+		kind := TypeReference
+		if ctx == CompositeReference || ctx == EmbedReference {
+			kind = ctx
+		}
+		rw.add(tn, kind, pos)
+		return
+	}
+	if fn, err := p.FindFunction(name); err == nil {
+		kind := ValueReference
+		if ctx == CallReference {
+			kind = ctx
+		}
+		rw.add(fn, kind, pos)
+		return
+	}
+	if v, err := p.FindVariable(name); err == nil {
+		rw.add(v, ValueReference, pos)
+		return
+	}
+	if c, err := p.FindConstant(name); err == nil {
+		rw.add(c, ValueReference, pos)
+	}
+}
+
+// importOf return the import if the expression is a package name
+func (rw *refWalker) importOf(e ast.Expr) *Import {
+	id, ok := e.(*ast.Ident)
+	if !ok || rw.locals[id.Name] {
+		return nil
+	}
+	et := newTyper(rw.pkg, rw.file)
+	if et.inScope(id.Name) {
+		return nil
+	}
+	if _, err := rw.pkg.FindFunction(id.Name); err == nil {
+		return nil
+	}
+	return getImport(id.Name, rw.file)
+}
+
+// ref handle the expression in the context
+func (rw *refWalker) ref(e ast.Expr, ctx ReferenceKind) {
+	switch t := e.(type) {
+	case *ast.Ident:
+		if !rw.locals[t.Name] {
+			rw.lookup(rw.pkg, t.Name, ctx, t.Pos())
+		}
+	case *ast.ParenExpr:
+		rw.ref(t.X, ctx)
+	case *ast.StarExpr:
+		// the *T in the embed
+		rw.ref(t.X, ctx)
+	case *ast.SelectorExpr:
+		if imp := rw.importOf(t.X); imp != nil {
+			if p, err := ParsePackage(imp.Path); err == nil {
+				rw.lookup(p, t.Sel.Name, ctx, t.Sel.Pos())
+			}
+			return
+		}
+		ast.Walk(rw, t.X)
+	default:
+		ast.Walk(rw, e)
+	}
+}
+
+// composite handle the composite literal, the keys of the struct literals are field names
+func (rw *refWalker) composite(c *ast.CompositeLit) {
+	keys := false
+	if c.Type != nil {
+		rw.ref(c.Type, CompositeReference)
+		et := newTyper(rw.pkg, rw.file)
+		switch et.underlying(getType(c.Type, rw.file.src, rw.file, rw.pkg)).(type) {
+		case *MapType, *ArrayType, *EllipsisType:
+			keys = true
+		}
+	}
+	for _, e := range c.Elts {
+		if kv, ok := e.(*ast.KeyValueExpr); ok {
+			if _, ident := kv.Key.(*ast.Ident); keys || !ident {
+				ast.Walk(rw, kv.Key)
+			}
+			ast.Walk(rw, kv.Value)
+			continue
+		}
+		ast.Walk(rw, e)
+	}
+}
+
+// fields walk the struct fields, the interface methods or the parameters. only the
+// parameter names are locals, the field and method names are not in the scope
+func (rw *refWalker) fields(fl *ast.FieldList, embed bool) {
+	if fl == nil {
+		return
+	}
+	for _, f := range fl.List {
+		if len(f.Names) == 0 && embed {
+			rw.ref(f.Type, EmbedReference)
+			continue
+		}
+		if !embed {
+			for _, n := range f.Names {
+				rw.locals[n.Name] = true
+			}
+		}
+		ast.Walk(rw, f.Type)
+	}
+}
+
+func (rw *refWalker) declare(exprs ...ast.Expr) {
+	for _, e := range exprs {
+		if id, ok := e.(*ast.Ident); ok {
+			rw.locals[id.Name] = true
+		}
+	}
+}
+
+func (rw *refWalker) Visit(node ast.Node) ast.Visitor {
+	switch t := node.(type) {
+	case *ast.Ident:
+		rw.ref(t, 0)
+		return nil
+	case *ast.SelectorExpr:
+		rw.ref(t, 0)
+		return nil
+	case *ast.CallExpr:
+		rw.ref(t.Fun, CallReference)
+		for _, a := range t.Args {
+			ast.Walk(rw, a)
+		}
+		return nil
+	case *ast.CompositeLit:
+		rw.composite(t)
+		return nil
+	case *ast.StructType:
+		rw.fields(t.Fields, true)
+		return nil
+	case *ast.InterfaceType:
+		rw.fields(t.Methods, true)
+		return nil
+	case *ast.FuncType:
+		rw.fields(t.Params, false)
+		rw.fields(t.Results, false)
+		return nil
+	case *ast.AssignStmt:
+		for _, e := range t.Rhs {
+			ast.Walk(rw, e)
+		}
+		if t.Tok == token.DEFINE {
+			rw.declare(t.Lhs...)
+			return nil
+		}
+		for _, e := range t.Lhs {
+			ast.Walk(rw, e)
+		}
+		return nil
+	case *ast.RangeStmt:
+		ast.Walk(rw, t.X)
+		if t.Tok == token.DEFINE {
+			rw.declare(t.Key, t.Value)
+		}
+		ast.Walk(rw, t.Body)
+		return nil
+	case *ast.ValueSpec:
+		if t.Type != nil {
+			ast.Walk(rw, t.Type)
+		}
+		for _, v := range t.Values {
+			ast.Walk(rw, v)
+		}
+		for _, n := range t.Names {
+			rw.declare(n)
+		}
+		return nil
+	case *ast.TypeSpec:
+		ast.Walk(rw, t.Type)
+		return nil
+	case *ast.KeyValueExpr:
+		// the key value outside the composite literals, not possible
+		return nil
+	case *ast.LabeledStmt:
+		ast.Walk(rw, t.Stmt)
+		return nil
+	case *ast.BranchStmt:
+		return nil
+	}
+	return rw
+}
+
+// buildReferences create the index of the uses in the package
+func (p *Package) buildReferences() {
+	if p.refs != nil {
+		return
+	}
+	p.refs = make(map[interface{}][]*Reference)
+	for _, f := range p.Files {
+		if f.node == nil {
+			continue
+		}
+		for _, d := range f.node.Decls {
+			switch t := d.(type) {
+			case *ast.FuncDecl:
+				rw := &refWalker{pkg: p, file: f, locals: make(map[string]bool)}
+				fn := f.functionFor(t)
+				if fn != nil {
+					rw.enclosing = fn.Name
+				}
+				if t.Recv != nil {
+					rw.fields(t.Recv, false)
+				}
+				ast.Walk(rw, t.Type)
+				if t.Body != nil {
+					ast.Walk(rw, t.Body)
+				}
+				if fn != nil {
+					rw.methodCalls(fn)
+				}
+			case *ast.GenDecl:
+				for _, s := range t.Specs {
+					rw := &refWalker{pkg: p, file: f, locals: make(map[string]bool)}
+					switch spec := s.(type) {
+					case *ast.TypeSpec:
+						rw.enclosing = nameFromIdent(spec.Name)
+						ast.Walk(rw, spec.Type)
+					case *ast.ValueSpec:
+						// not the ast.Walk on the spec, the names are not locals
+						rw.enclosing = nameFromIdent(spec.Names[0])
+						if spec.Type != nil {
+							ast.Walk(rw, spec.Type)
+						}
+						for _, v := range spec.Values {
+							ast.Walk(rw, v)
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+// methodCalls add the method calls, the methods can not be found without the types
+// so they are from the function body call sites
+func (rw *refWalker) methodCalls(fn *Function) {
+	b := fn.Body()
+	if b == nil {
+		return
+	}
+	for _, c := range b.Calls {
+		if c.Function != nil && (c.Function.Receiver != nil || c.Interface != nil) {
+			rw.pkg.refs[c.Function] = append(rw.pkg.refs[c.Function], &Reference{
+				Kind:      CallReference,
+				Pos:       c.Pos,
+				File:      rw.file,
+				Package:   rw.pkg,
+				Enclosing: rw.enclosing,
+			})
+		}
+	}
+	for _, lit := range b.Literals {
+		rw.methodCalls(lit)
+	}
+}
+
+func (f *File) functionFor(d *ast.FuncDecl) *Function {
+	for _, fn := range f.Functions {
+		if fn.decl == d {
+			return fn
+		}
+	}
+	return nil
+}
+
+func sortReferences(refs []*Reference) {
+	sort.SliceStable(refs, func(i, j int) bool {
+		a, b := refs[i].Pos, refs[j].Pos
+		if a.Filename != b.Filename {
+			return a.Filename < b.Filename
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+}
+
+// References return the uses of the declaration in this package. the decl is a
+// *TypeName, *Function, *Variable or *Constant. the index is built on the first call
+func (p *Package) References(decl interface{}) []*Reference {
+	p.buildReferences()
+	res := append([]*Reference(nil), p.refs[decl]...)
+	sortReferences(res)
+	return res
+}
+
+// FindReferences return the uses of the declaration in all the loaded packages
+func FindReferences(decl interface{}) []*Reference {
+	lock.RLock()
+	var pkgs []*Package
+	seen := make(map[*Package]bool)
+	for _, p := range packageCache {
+		if !seen[p] {
+			seen[p] = true
+			pkgs = append(pkgs, p)
+		}
+	}
+	lock.RUnlock()
+
+	var res []*Reference
+	for _, p := range pkgs {
+		res = append(res, p.References(decl)...)
+	}
+	sortReferences(res)
+	return res
+}
@@ -0,0 +1,141 @@
+package humanize
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+var refSrc = `package refs
+
+import (
+	str "strings"
+)
+
+type Base struct{}
+
+func (b *Base) Close() error { return nil }
+
+type Node struct {
+	Base
+	Next  *Node
+	Names []string
+}
+
+const Max = 10
+
+var root = &Node{Next: nil}
+
+var count = Max * 2
+
+func NewNode(name string) *Node {
+	n := &Node{Names: []string{name}}
+	n.Close()
+	return n
+}
+
+func build() {
+	var b str.Builder
+	b.WriteString(str.ToUpper("x"))
+	Node := 1
+	_ = Node
+	list := map[string]Node{"a": {}}
+	_ = list
+	f := NewNode
+	f("y")
+	NewNode("z")
+	for i := 0; i < Max; i++ {
+	}
+}
+
+func shadow() {
+	// the field names are not locals
+	var v struct{ Max int }
+	_ = v
+	_ = Max
+}
+`
+
+func refPositions(refs []*Reference) []string {
+	var res []string
+	for _, r := range refs {
+		res = append(res, r.Kind.String()+"@"+r.Enclosing)
+	}
+	return res
+}
+
+func TestReference(t *testing.T) {
+	Convey("Reference test", t, func() {
+		var p = &Package{Path: "example.com/refs", Name: "refs"}
+		f, err := parseFile("refs.go", refSrc, p)
+		So(err, ShouldBeNil)
+		p.Files = append(p.Files, f)
+		So(lateBind(p), ShouldBeNil)
+
+		Convey("types", func() {
+			tn, err := p.FindType("Node")
+			So(err, ShouldBeNil)
+			refs := p.References(tn)
+			So(refPositions(refs), ShouldResemble, []string{
+				"type@Node",
+				"composite@root",
+				"type@NewNode",
+				"composite@NewNode",
+			})
+			So(refs[0].Pos.Filename, ShouldEqual, "refs.go")
+			So(refs[0].Pos.Line, ShouldEqual, 13)
+			So(refs[0].File, ShouldEqual, f)
+			So(refs[0].Package, ShouldEqual, p)
+
+			base, err := p.FindType("Base")
+			So(err, ShouldBeNil)
+			So(refPositions(p.References(base)), ShouldResemble, []string{"type@Base.Close", "embed@Node"})
+		})
+
+		Convey("values and calls", func() {
+			c, err := p.FindConstant("Max")
+			So(err, ShouldBeNil)
+			So(refPositions(p.References(c)), ShouldResemble, []string{"value@count", "value@build", "value@shadow"})
+
+			fn, err := p.FindFunction("NewNode")
+			So(err, ShouldBeNil)
+			So(refPositions(p.References(fn)), ShouldResemble, []string{"value@build", "call@build"})
+
+			m, err := p.FindFunction("Base.Close")
+			So(err, ShouldBeNil)
+			refs := p.References(m)
+			So(refPositions(refs), ShouldResemble, []string{"call@NewNode"})
+			So(refs[0].Pos.Line, ShouldEqual, 25)
+		})
+
+		Convey("imported", func() {
+			sp, err := ParsePackage("strings")
+			So(err, ShouldBeNil)
+			b, err := sp.FindType("Builder")
+			So(err, ShouldBeNil)
+			So(refPositions(p.References(b)), ShouldResemble, []string{"type@build"})
+			up, err := sp.FindFunction("ToUpper")
+			So(err, ShouldBeNil)
+			So(refPositions(p.References(up)), ShouldResemble, []string{"call@build"})
+			ws, err := sp.FindFunction("Builder.WriteString")
+			So(err, ShouldBeNil)
+			So(refPositions(p.References(ws)), ShouldResemble, []string{"call@build"})
+
+			// only these two packages, the other tests load a lot of packages
+			lock.Lock()
+			old := packageCache
+			packageCache = map[string]*Package{p.Path: p, sp.Path: sp}
+			lock.Unlock()
+			defer func() {
+				lock.Lock()
+				packageCache = old
+				lock.Unlock()
+			}()
+			So(len(FindReferences(up)), ShouldEqual, 1)
+			// the strings package is using the builder too
+			refs := FindReferences(b)
+			So(len(refs), ShouldBeGreaterThan, 1)
+			So(refs[len(refs)-1].Package, ShouldEqual, p)
+		})
+	})
+}
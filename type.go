@@ -1,8 +1,12 @@
 package humanize
 
 import (
+	"bytes"
 	"fmt"
 	"go/ast"
+	"go/printer"
+	"go/token"
+	"go/types"
 	"reflect"
 	"strconv"
 	"strings"
@@ -51,8 +55,9 @@ type Field struct {
 // Embed is the embeded type in the struct or interface
 type Embed struct {
 	Type
-	Docs Docs
-	Tags reflect.StructTag
+	Docs    Docs
+	Comment Docs
+	Tags    reflect.StructTag
 }
 
 // StructType is a struct in source code
@@ -111,16 +116,36 @@ type FuncType struct {
 
 	Parameters []*Variable
 	Results    []*Variable
+	// Variadic is true if the last parameter is ...T, the type of that parameter is []T
+	Variadic bool
+	// ParameterGroups and ResultGroups is the number of the variables in each group, like
+	// [1 2] for (a string, b, c int). nil means each variable is in its own group
+	ParameterGroups []int
+	ResultGroups    []int
 }
 
 //TypeName contain type and its name, means the type is in this package
 type TypeName struct {
-	Type Type
-	Name string
-	Docs Docs
+	Type        Type
+	Name        string
+	Docs        Docs
+	Annotations Annotations
+	Directives  []*Directive
+	// GroupDocs is the docs of the declaration group, like type ( ... )
+	GroupDocs Docs
+	// SpecDocs is the docs of the declaration itself, Docs is both of them
+	SpecDocs Docs
+	// Comment is the trailing comment
+	Comment Docs
+	// Scope is the function that the type is declared in, nil for the package level types
+	Scope *Function
 
 	Methods     []*Function
 	StarMethods []*Function
+
+	obj     *types.TypeName // only in ParsePackageWithTypes
+	pos     token.Pos       // the position of the spec, to print the file in the source order
+	generic bool            // the type parameters are not in the model
 }
 
 // Package in selector type is not this package
@@ -148,6 +173,8 @@ func (tn TypeName) GetDefinition() string {
 	return tn.Name + " " + tn.Type.GetDefinition()
 }
 
+// getTypeName return the type name of the type, it is nil if the type is not found,
+// like the types from the packages that can not be loaded
 func getTypeName(t Type) (*TypeName, bool) {
 	var pointer bool
 	if t2, ok := t.(*StarType); ok {
@@ -155,20 +182,29 @@ func getTypeName(t Type) (*TypeName, bool) {
 		pointer = true
 	}
 
+	name := t.GetDefinition()
+	p := t.Package()
 	if t2, ok := t.(*SelectorType); ok {
 		// its in another package, load it from there
-		p := t2.pkg.LoadPackage()
-		t3, err := p.FindType(t2.Type.GetDefinition())
-		assertNil(err)
-		return t3, pointer
+		if t2.pkg == nil {
+			return nil, pointer
+		}
+		name, p = t2.Type.GetDefinition(), t2.pkg.LoadPackage()
+	}
+	if p == nil {
+		return nil, pointer
+	}
+	tn, err := p.FindType(name)
+	if err != nil {
+		return nil, pointer
 	}
-
-	tn, err := t.Package().FindType(t.GetDefinition())
-	assertNil(err)
 	return tn, pointer
 }
 
 func (tn TypeName) GetAllMethods(pointer bool) []*Function {
+	if tn.obj != nil {
+		return tn.typedMethods(pointer)
+	}
 	met := tn.Methods
 	if pointer {
 		met = append(met, tn.StarMethods...)
@@ -180,6 +216,10 @@ func (tn TypeName) GetAllMethods(pointer bool) []*Function {
 		// both can be pointer, if pointer then the StartMethods are available
 		for i := range st.Embeds {
 			tn, pn := getTypeName(st.Embeds[i].Type)
+			if tn == nil {
+				// the methods of the unknown types are unknown too
+				continue
+			}
 			met = append(met, tn.GetAllMethods(pn)...)
 		}
 	}
@@ -187,24 +227,37 @@ func (tn TypeName) GetAllMethods(pointer bool) []*Function {
 	return met
 }
 
-func getInterfaceFunc(in *InterfaceType) []*Function {
+// getInterfaceFunc return all the functions of the interface, false if there is an
+// embedded interface that is not found
+func getInterfaceFunc(in *InterfaceType) ([]*Function, bool) {
 	fn := in.Functions
 	for i := range in.Embed {
-		p := in.Embed[i].Package()
-		tn, err := p.FindType(removeReceiver(in.Embed[i].GetDefinition()))
-		assertNil(err)
-		ni := tn.Type.(*InterfaceType)
-		fn = append(fn, getInterfaceFunc(ni)...)
+		tn, _ := getTypeName(in.Embed[i])
+		if tn == nil {
+			return nil, false
+		}
+		ni, ok := tn.Type.(*InterfaceType)
+		if !ok {
+			return nil, false
+		}
+		efn, ok := getInterfaceFunc(ni)
+		if !ok {
+			return nil, false
+		}
+		fn = append(fn, efn...)
 	}
-	return fn
+	return fn, true
 }
 
 // Support return true if the type support the interface, if pointer is true then it checked with
-// pointer receiver
+// pointer receiver. it is false if the interface has an embedded interface that is not found
 func (tn TypeName) Support(in *InterfaceType, pointer bool) bool {
 	two := tn.GetAllMethods(pointer)
 
-	one := getInterfaceFunc(in)
+	one, ok := getInterfaceFunc(in)
+	if !ok {
+		return false
+	}
 
 	return compare(one, two)
 }
@@ -271,11 +324,58 @@ func (i *FuncType) getDefinitionWithName(name string) string {
 	return "func " + name + i.getSign()
 }
 
+// Signature return the go source of the function signature, like
+// func Name(a, b int, rest ...string) (n int, err error). the name is optional, and if
+// withNames is false the names of the parameters and results are removed. the types
+// that are not in the model, like List[int], are the original source or "?"
+func (i *FuncType) Signature(name string, withNames bool) string {
+	ft := &FuncType{srcBase: i.srcBase, Variadic: i.Variadic}
+	if withNames {
+		ft.ParameterGroups, ft.ResultGroups = i.ParameterGroups, i.ResultGroups
+	}
+	for _, v := range i.Parameters {
+		ft.Parameters = append(ft.Parameters, signatureVariable(v, withNames))
+	}
+	for _, v := range i.Results {
+		ft.Results = append(ft.Results, signatureVariable(v, withNames))
+	}
+	var node ast.Node = ToAstExpr(ft, nil)
+	if name != "" {
+		node = &ast.FuncDecl{Name: ast.NewIdent(name), Type: node.(*ast.FuncType)}
+	}
+	buf := &bytes.Buffer{}
+	if err := printer.Fprint(buf, token.NewFileSet(), node); err != nil {
+		return ""
+	}
+	return buf.String()
+}
+
+// signatureVariable return a copy of the variable for the signature, the type is a
+// placeholder if it can not be converted
+func signatureVariable(v *Variable, withName bool) *Variable {
+	res := &Variable{Type: v.Type}
+	if withName {
+		res.Name = v.Name
+	}
+	if ToAstExpr(v.Type, nil) != nil {
+		return res
+	}
+	res.Type = &IdentType{Ident: "?"}
+	if v.typeExpr != nil && v.file != nil {
+		res.Type = &IdentType{Ident: getSource(v.typeExpr, v.file.src)}
+	}
+	return res
+}
+
 // GetSign the name of this type
 func (i *FuncType) getSign() string {
 	var args, res []string
 	for a := range i.Parameters {
-		args = append(args, i.Parameters[a].Type.GetDefinition())
+		def := i.Parameters[a].Type.GetDefinition()
+		if i.Variadic && a == len(i.Parameters)-1 {
+			def = "..." + strings.TrimPrefix(def, "[]")
+		}
+		args = append(args, def)
 	}
 
 	for a := range i.Results {
@@ -320,7 +420,7 @@ func (i *InterfaceType) GetDefinition() string {
 	return res + "}"
 }
 
-func getSource(e ast.Expr, src string) string {
+func getSource(e ast.Node, src string) string {
 	res := ""
 	start := e.Pos() - 1
 	end := e.End() - 1
@@ -399,16 +499,18 @@ func getType(e ast.Expr, src string, f *File, p *Package) Type {
 						Type: getType(s.Type, src, f, p),
 					}
 
-					f := Field{
+					fld := Field{
 						v,
 						"",
 					}
 					if s.Tag != nil {
-						f.Tags = reflect.StructTag(s.Tag.Value)
-						f.Tags = f.Tags[1 : len(f.Tags)-1]
+						fld.Tags = reflect.StructTag(s.Tag.Value)
+						fld.Tags = fld.Tags[1 : len(fld.Tags)-1]
 					}
-					f.Docs = docsFromNodeDoc(s.Doc)
-					res.Fields = append(res.Fields, &f)
+					fld.Docs = docsFromNodeDoc(s.Doc)
+					fld.Comment = append(inlineComment(f, s.Names, i, s.Type.Pos()), docsFromNodeDoc(s.Comment)...)
+					fld.Annotations = annotationsFromNodeDoc(f, s.Doc)
+					res.Fields = append(res.Fields, &fld)
 				}
 			} else {
 				e := Embed{
@@ -419,6 +521,7 @@ func getType(e ast.Expr, src string, f *File, p *Package) Type {
 					e.Tags = e.Tags[1 : len(e.Tags)-1]
 				}
 				e.Docs = docsFromNodeDoc(s.Doc)
+				e.Comment = docsFromNodeDoc(s.Comment)
 				res.Embeds = append(res.Embeds, &e)
 			}
 		}
@@ -436,6 +539,7 @@ func getType(e ast.Expr, src string, f *File, p *Package) Type {
 				res.Name = nameFromIdent(t.Methods.List[i].Names[0])
 
 				res.Docs = docsFromNodeDoc(t.Methods.List[i].Doc)
+				res.Annotations = annotationsFromNodeDoc(f, t.Methods.List[i].Doc)
 				typ := getType(t.Methods.List[i].Type, src, f, p)
 				res.Type = typ.(*FuncType)
 				iface.Functions = append(iface.Functions, &res)
@@ -460,10 +564,13 @@ func getType(e ast.Expr, src string, f *File, p *Package) Type {
 			Type:    getType(t.Sel, src, f, p),
 		}
 	case *ast.FuncType:
-		return &FuncType{
-			srcBase:    srcBase{p, getSource(e, src)},
-			Parameters: extractVariableList(t.Params, src, f, p),
-			Results:    extractVariableList(t.Results, src, f, p),
+		return newFuncType(t, getSource(e, src), src, f, p)
+	case *ast.Ellipsis:
+		// only in the variadic parameters
+		return &ArrayType{
+			srcBase: srcBase{p, getSource(e, src)},
+			Slice:   true,
+			Type:    getType(t.Elt, src, f, p),
 		}
 	}
 
@@ -474,8 +581,15 @@ func getType(e ast.Expr, src string, f *File, p *Package) Type {
 func NewType(t *ast.TypeSpec, c *ast.CommentGroup, src string, f *File, p *Package) *TypeName {
 	doc := docsFromNodeDoc(c, t.Doc)
 	return &TypeName{
-		Docs: doc,
-		Type: getType(t.Type, src, f, p),
-		Name: nameFromIdent(t.Name),
+		Docs:        doc,
+		GroupDocs:   docsFromNodeDoc(c),
+		SpecDocs:    docsFromNodeDoc(t.Doc),
+		Comment:     docsFromNodeDoc(t.Comment),
+		Annotations: annotationsFromNodeDoc(f, c, t.Doc),
+		Directives:  directivesFromNodeDoc(f, c, t.Doc),
+		Type:        getType(t.Type, src, f, p),
+		Name:        nameFromIdent(t.Name),
+		pos:         t.Pos(),
+		generic:     t.TypeParams != nil,
 	}
 }
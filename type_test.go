@@ -260,7 +260,10 @@ func TestType(t *testing.T) {
 			lateBind(p)
 			t, err := p.FindVariable("on")
 			So(err, ShouldBeNil)
-			sel, ok := t.Type.(*SelectorType)
+			// onion.New return *onion.Onion
+			star, ok := t.Type.(*StarType)
+			So(ok, ShouldBeTrue)
+			sel, ok := star.Target.(*SelectorType)
 			So(ok, ShouldBeTrue)
 			p2 := sel.Package()
 			So(p2.Path, ShouldEqual, "github.com/fzerorubigd/onion")
@@ -306,3 +309,49 @@ func TestType(t *testing.T) {
 
 	})
 }
+
+const unknownEmbeds = `
+package example
+
+import "example.com/missing"
+
+type Closer interface {
+	Close() error
+}
+
+type ReadCloser interface {
+	missing.Reader
+	Close() error
+}
+
+type File struct {
+	missing.Base
+}
+
+func (f *File) Close() error {
+	return nil
+}
+`
+
+func TestSupportUnknown(t *testing.T) {
+	Convey("support with unknown embeds", t, func() {
+		p := &Package{}
+		f, err := ParseFile(unknownEmbeds, p)
+		So(err, ShouldBeNil)
+		p.Files = append(p.Files, f)
+		So(lateBind(p), ShouldBeNil)
+
+		tn, err := p.FindType("File")
+		So(err, ShouldBeNil)
+		So(len(tn.GetAllMethods(true)), ShouldEqual, 1)
+
+		c, err := p.FindType("Closer")
+		So(err, ShouldBeNil)
+		So(tn.Support(c.Type.(*InterfaceType), true), ShouldBeTrue)
+		So(tn.Support(c.Type.(*InterfaceType), false), ShouldBeFalse)
+
+		rc, err := p.FindType("ReadCloser")
+		So(err, ShouldBeNil)
+		So(tn.Support(rc.Type.(*InterfaceType), true), ShouldBeFalse)
+	})
+}
@@ -0,0 +1,743 @@
+package humanize
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+)
+
+// predeclared is the list of go builtin types
+var predeclared = map[string]bool{
+	"bool": true, "byte": true, "complex64": true, "complex128": true, "error": true,
+	"float32": true, "float64": true, "int": true, "int8": true, "int16": true,
+	"int32": true, "int64": true, "rune": true, "string": true, "uint": true,
+	"uint8": true, "uint16": true, "uint32": true, "uint64": true, "uintptr": true,
+	"any": true, "comparable": true,
+}
+
+// inferError is the error when the type of an expression is not found. soft errors
+// are the ones that we can not decide about (like a missing import because of build
+// tags), they are reported as diagnostic not error
+type inferError struct {
+	msg  string
+	soft bool
+}
+
+func (e *inferError) Error() string {
+	return e.msg
+}
+
+func hardError(format string, args ...interface{}) error {
+	return &inferError{msg: fmt.Sprintf(format, args...)}
+}
+
+func softError(format string, args ...interface{}) error {
+	return &inferError{msg: fmt.Sprintf(format, args...), soft: true}
+}
+
+func isSoft(err error) bool {
+	e, ok := err.(*inferError)
+	return ok && e.soft
+}
+
+// exprTyper find the type of package level expressions, and the function level
+// ones if the locals is set
+type exprTyper struct {
+	pkg  *Package
+	file *File
+	// locals is the local variables of a function, nil type means unknown
+	locals map[string]Type
+	// localTypes is the types declared in the function
+	localTypes map[string]*TypeName
+
+	resolving map[*Variable]bool
+}
+
+func newTyper(p *Package, f *File) *exprTyper {
+	return &exprTyper{pkg: p, file: f, resolving: make(map[*Variable]bool)}
+}
+
+func (et *exprTyper) forFile(f *File) *exprTyper {
+	return &exprTyper{pkg: et.pkg, file: f, resolving: et.resolving}
+}
+
+func (et *exprTyper) ident(name string) *IdentType {
+	return &IdentType{srcBase: srcBase{pkg: et.pkg}, Ident: name}
+}
+
+// typeExpr return the type if the expression is a type, not a value
+func (et *exprTyper) typeExpr(e ast.Expr) Type {
+	switch t := e.(type) {
+	case *ast.Ident:
+		if predeclared[t.Name] {
+			return et.ident(t.Name)
+		}
+		if _, ok := et.localTypes[t.Name]; ok {
+			return et.ident(t.Name)
+		}
+		if _, err := et.pkg.FindType(t.Name); err == nil {
+			return et.ident(t.Name)
+		}
+	case *ast.ParenExpr:
+		return et.typeExpr(t.X)
+	case *ast.StarExpr:
+		if inner := et.typeExpr(t.X); inner != nil {
+			return &StarType{srcBase: srcBase{pkg: et.pkg}, Target: inner}
+		}
+	case *ast.SelectorExpr:
+		id, ok := t.X.(*ast.Ident)
+		if !ok {
+			return nil
+		}
+		imp := getImport(nameFromIdent(id), et.file)
+		if imp == nil {
+			return nil
+		}
+		if pkg := imp.LoadPackage(); pkg != nil {
+			if _, err := pkg.FindType(nameFromIdent(t.Sel)); err == nil {
+				return getType(t, et.file.src, et.file, et.pkg)
+			}
+		}
+	case *ast.ArrayType, *ast.MapType, *ast.ChanType, *ast.FuncType, *ast.InterfaceType, *ast.StructType:
+		return getType(t, et.file.src, et.file, et.pkg)
+	}
+	return nil
+}
+
+// importFor return the import of the package in the current file, or a new one
+// if the file is not importing it
+func (et *exprTyper) importFor(p *Package) *Import {
+	for _, i := range et.file.Imports {
+		if i.Path == p.Path {
+			return i
+		}
+	}
+	return &Import{Name: p.Name, Path: p.Path}
+}
+
+// localize convert a type from another package to a type in this package,
+// the type names of that package are changed to selector types
+func (et *exprTyper) localize(t Type) Type {
+	base := srcBase{pkg: et.pkg}
+	switch x := t.(type) {
+	case *IdentType:
+		from := x.Package()
+		if from == nil || from == et.pkg || predeclared[x.Ident] {
+			return x
+		}
+		if _, err := from.FindType(x.Ident); err != nil {
+			return x
+		}
+		return &SelectorType{srcBase: base, pkg: et.importFor(from), Type: x}
+	case *SelectorType:
+		if x.pkg == nil || x.pkg.Path != et.pkg.Path || et.pkg.Path == "" {
+			return x
+		}
+		return et.ident(x.Type.GetDefinition())
+	case *StarType:
+		return &StarType{srcBase: base, Target: et.localize(x.Target)}
+	case *EllipsisType:
+		return &EllipsisType{&ArrayType{srcBase: base, Slice: x.Slice, Len: x.Len, Type: et.localize(x.Type)}}
+	case *ArrayType:
+		return &ArrayType{srcBase: base, Slice: x.Slice, Len: x.Len, Type: et.localize(x.Type)}
+	case *MapType:
+		return &MapType{srcBase: base, Key: et.localize(x.Key), Value: et.localize(x.Value)}
+	case *ChannelType:
+		return &ChannelType{srcBase: base, Direction: x.Direction, Type: et.localize(x.Type)}
+	case *FuncType:
+		res := &FuncType{srcBase: base, Variadic: x.Variadic, ParameterGroups: x.ParameterGroups, ResultGroups: x.ResultGroups}
+		for _, v := range x.Parameters {
+			res.Parameters = append(res.Parameters, &Variable{Name: v.Name, Type: et.localize(v.Type)})
+		}
+		for _, v := range x.Results {
+			res.Results = append(res.Results, &Variable{Name: v.Name, Type: et.localize(v.Type)})
+		}
+		return res
+	}
+	return t
+}
+
+// typeName return the type name of a named type, and the package of it
+func (et *exprTyper) typeName(t Type) (*TypeName, *Package) {
+	switch x := t.(type) {
+	case *IdentType:
+		if predeclared[x.Ident] {
+			return nil, nil
+		}
+		p := x.Package()
+		if p == nil {
+			p = et.pkg
+		}
+		if tn, ok := et.localTypes[x.Ident]; ok && p == et.pkg {
+			return tn, p
+		}
+		tn, err := p.FindType(x.Ident)
+		if err != nil {
+			return nil, nil
+		}
+		return tn, p
+	case *SelectorType:
+		if x.pkg == nil {
+			return nil, nil
+		}
+		p := x.pkg.LoadPackage()
+		if p == nil {
+			return nil, nil
+		}
+		tn, err := p.FindType(x.Type.GetDefinition())
+		if err != nil {
+			return nil, nil
+		}
+		return tn, p
+	}
+	return nil, nil
+}
+
+// underlying return the underlying type of a named type, localized in this package
+func (et *exprTyper) underlying(t Type) Type {
+	for i := 0; i < 100; i++ {
+		tn, _ := et.typeName(t)
+		if tn == nil || tn.Type == nil {
+			return t
+		}
+		t = et.localize(tn.Type)
+	}
+	return t
+}
+
+// member find a field or method of a type
+func (et *exprTyper) member(t Type, name string, depth int) (Type, error) {
+	if depth > 10 {
+		return nil, softError("too deep embedding for %s", name)
+	}
+	if st, ok := t.(*StarType); ok {
+		t = st.Target
+	}
+
+	if tn, p := et.typeName(t); tn != nil {
+		findMethods(p)
+		for _, fn := range append(tn.Methods, tn.StarMethods...) {
+			if removeReceiver(fn.Name) == name {
+				return et.localize(fn.Type), nil
+			}
+		}
+	}
+
+	switch u := et.underlying(t).(type) {
+	case *StructType:
+		for _, f := range u.Fields {
+			if f.Name == name {
+				return et.localize(f.Type), nil
+			}
+		}
+		for _, e := range u.Embeds {
+			embed := et.localize(e.Type)
+			if removeReceiver(removeStar(embed.GetDefinition())) == name {
+				return embed, nil
+			}
+			if res, err := et.member(embed, name, depth+1); err == nil {
+				return res, nil
+			}
+		}
+	case *InterfaceType:
+		for _, fn := range u.Functions {
+			if fn.Name == name {
+				return et.localize(fn.Type), nil
+			}
+		}
+		for _, e := range u.Embed {
+			if res, err := et.member(et.localize(e), name, depth+1); err == nil {
+				return res, nil
+			}
+		}
+	}
+
+	return nil, softError("can not find the field or method %s in %s", name, t.GetDefinition())
+}
+
+// method find the method of a type, including the promoted ones and the interface
+// methods, and the package that it is declared in. the interface is set for the
+// interface methods
+func (et *exprTyper) method(t Type, name string, depth int) (*Function, *Package, *InterfaceType) {
+	if depth > 10 {
+		return nil, nil, nil
+	}
+	if st, ok := t.(*StarType); ok {
+		t = st.Target
+	}
+
+	tn, p := et.typeName(t)
+	if tn != nil {
+		findMethods(p)
+		for _, fn := range append(tn.Methods, tn.StarMethods...) {
+			if removeReceiver(fn.Name) == name {
+				return fn, p, nil
+			}
+		}
+	}
+	if p == nil {
+		p = et.pkg
+	}
+
+	switch u := et.underlying(t).(type) {
+	case *StructType:
+		for _, e := range u.Embeds {
+			if fn, fp, in := et.method(et.localize(e.Type), name, depth+1); fn != nil {
+				return fn, fp, in
+			}
+		}
+	case *InterfaceType:
+		for _, fn := range u.Functions {
+			if fn.Name == name {
+				return fn, p, u
+			}
+		}
+		for _, e := range u.Embed {
+			if fn, fp, in := et.method(et.localize(e), name, depth+1); fn != nil {
+				return fn, fp, in
+			}
+		}
+	}
+	return nil, nil, nil
+}
+
+func removeStar(s string) string {
+	if len(s) > 0 && s[0] == '*' {
+		return s[1:]
+	}
+	return s
+}
+
+// untyped return true if the expression is an untyped constant expression
+func (et *exprTyper) untyped(e ast.Expr) bool {
+	switch t := e.(type) {
+	case *ast.BasicLit:
+		return true
+	case *ast.ParenExpr:
+		return et.untyped(t.X)
+	case *ast.UnaryExpr:
+		return t.Op != token.AND && t.Op != token.ARROW && et.untyped(t.X)
+	case *ast.BinaryExpr:
+		return et.untyped(t.X) && et.untyped(t.Y)
+	case *ast.Ident:
+		if t.Name == "true" || t.Name == "false" {
+			return true
+		}
+		c, err := et.pkg.FindConstant(t.Name)
+		return err == nil && c.typeExpr == nil && !isConversion(c.expr)
+	}
+	return false
+}
+
+func isConversion(e ast.Expr) bool {
+	_, ok := e.(*ast.CallExpr)
+	return ok
+}
+
+// untypedRank is used to find the result of mixing untyped constants
+var untypedRank = map[string]int{"int": 1, "rune": 2, "float64": 3, "complex128": 4}
+
+func (et *exprTyper) literal(t *ast.BasicLit) Type {
+	switch t.Kind {
+	case token.INT:
+		return et.ident("int")
+	case token.FLOAT:
+		return et.ident("float64")
+	case token.IMAG:
+		return et.ident("complex128")
+	case token.CHAR:
+		return et.ident("rune")
+	default:
+		return et.ident("string")
+	}
+}
+
+// varType return the type of a package variable, inferring it if needed
+func (et *exprTyper) varType(v *Variable) (Type, error) {
+	if v.Type != nil {
+		return v.Type, nil
+	}
+	if v.expr == nil {
+		return nil, softError("variable %s has no type and no value", v.Name)
+	}
+	if et.resolving[v] {
+		return nil, softError("initialization cycle for %s", v.Name)
+	}
+	et.resolving[v] = true
+	defer delete(et.resolving, v)
+
+	f := v.file
+	if f == nil {
+		f = et.file
+	}
+	t, err := et.forFile(f).typeOfIndex(v.expr, v.indx)
+	if err != nil {
+		return nil, err
+	}
+	v.Type = t
+	return t, nil
+}
+
+func (et *exprTyper) constType(c *Constant) (Type, error) {
+	if c.Type != nil {
+		if id, ok := c.Type.(*IdentType); !ok || id.Ident != "iota" {
+			return c.Type, nil
+		}
+		return et.ident("int"), nil
+	}
+	if c.expr == nil {
+		return nil, softError("constant %s has no value", c.Name)
+	}
+	return et.typeOf(c.expr)
+}
+
+// identType return the type of an identifier in package scope
+func (et *exprTyper) identType(t *ast.Ident) (Type, error) {
+	name := nameFromIdent(t)
+	if lt, ok := et.locals[name]; ok {
+		if lt == nil {
+			return nil, softError("can not find the type of local %s", name)
+		}
+		return lt, nil
+	}
+	if v, err := et.pkg.FindVariable(name); err == nil {
+		return et.varType(v)
+	}
+	if c, err := et.pkg.FindConstant(name); err == nil {
+		return et.constType(c)
+	}
+	if fn, err := et.pkg.FindFunction(name); err == nil {
+		return fn.Type, nil
+	}
+	switch name {
+	case "true", "false":
+		return et.ident("bool"), nil
+	case "nil":
+		return nil, softError("use of untyped nil")
+	}
+
+	return nil, hardError("can not find the identifier %s", name)
+}
+
+// selectorType handle the pkg.Name and value.Name expressions
+func (et *exprTyper) selectorType(t *ast.SelectorExpr) (Type, error) {
+	if id, ok := t.X.(*ast.Ident); ok && !et.inScope(nameFromIdent(id)) {
+		pkgName := nameFromIdent(id)
+		imp := getImport(pkgName, et.file)
+		if imp == nil {
+			// TODO : package currently is not capable of parsing build tags.
+			return nil, softError("can not find the import %s", pkgName)
+		}
+		pkg, err := ParsePackage(imp.Path)
+		if err != nil {
+			return nil, err
+		}
+		name := nameFromIdent(t.Sel)
+		if v, err := pkg.FindVariable(name); err == nil {
+			vt, err := newTyper(pkg, v.file).varType(v)
+			if err != nil {
+				return nil, err
+			}
+			return et.localize(vt), nil
+		}
+		if c, err := pkg.FindConstant(name); err == nil {
+			ct, err := newTyper(pkg, c.file).constType(c)
+			if err != nil {
+				return nil, err
+			}
+			return et.localize(ct), nil
+		}
+		if fn, err := pkg.FindFunction(name); err == nil {
+			return et.localize(fn.Type), nil
+		}
+		return nil, hardError("can not find the %s in package %s", name, imp.Path)
+	}
+
+	x, err := et.typeOf(t.X)
+	if err != nil {
+		return nil, err
+	}
+	return et.member(x, nameFromIdent(t.Sel), 0)
+}
+
+// inScope check if the name is declared in package scope, so it shadows the imports
+func (et *exprTyper) inScope(name string) bool {
+	if _, ok := et.locals[name]; ok {
+		return true
+	}
+	if _, err := et.pkg.FindVariable(name); err == nil {
+		return true
+	}
+	if _, err := et.pkg.FindConstant(name); err == nil {
+		return true
+	}
+	return false
+}
+
+func (et *exprTyper) builtinCall(name string, c *ast.CallExpr) (Type, bool, error) {
+	switch name {
+	case "len", "cap", "copy":
+		return et.ident("int"), true, nil
+	case "real", "imag":
+		return et.ident("float64"), true, nil
+	case "complex":
+		return et.ident("complex128"), true, nil
+	case "recover":
+		return &InterfaceType{srcBase: srcBase{pkg: et.pkg}}, true, nil
+	case "new":
+		if len(c.Args) != 1 {
+			return nil, true, hardError("invalid new call")
+		}
+		t := et.typeExpr(c.Args[0])
+		if t == nil {
+			var err error
+			if t, err = et.typeOf(c.Args[0]); err != nil {
+				return nil, true, err
+			}
+		}
+		return &StarType{srcBase: srcBase{pkg: et.pkg}, Target: t}, true, nil
+	case "make":
+		if len(c.Args) == 0 {
+			return nil, true, hardError("invalid make call")
+		}
+		t := et.typeExpr(c.Args[0])
+		if t == nil {
+			return nil, true, hardError("invalid type in make call")
+		}
+		return t, true, nil
+	case "append", "min", "max":
+		if len(c.Args) == 0 {
+			return nil, true, hardError("invalid %s call", name)
+		}
+		for i := range c.Args {
+			if !et.untyped(c.Args[i]) || i == len(c.Args)-1 {
+				t, err := et.typeOf(c.Args[i])
+				return t, true, err
+			}
+		}
+	case "panic", "print", "println", "close", "delete", "clear":
+		return nil, true, hardError("%s has no value", name)
+	}
+
+	return nil, false, nil
+}
+
+// callResults return the result list of a call expression
+func (et *exprTyper) callType(c *ast.CallExpr, indx int) (Type, error) {
+	if id, ok := c.Fun.(*ast.Ident); ok && !et.inScope(nameFromIdent(id)) {
+		if _, err := et.pkg.FindFunction(nameFromIdent(id)); err != nil {
+			if t, ok, err := et.builtinCall(nameFromIdent(id), c); ok {
+				return t, err
+			}
+		}
+	}
+
+	// type conversion
+	if t := et.typeExpr(c.Fun); t != nil {
+		if len(c.Args) != 1 {
+			return nil, hardError("it can not be a typecast : %s", getSource(c.Fun, et.file.src))
+		}
+		return t, nil
+	}
+
+	ft, err := et.typeOf(c.Fun)
+	if err != nil {
+		if isSoft(err) {
+			return nil, err
+		}
+		return nil, hardError("can not find the call for %s: %s", getSource(c.Fun, et.file.src), err)
+	}
+	fn, ok := et.underlying(ft).(*FuncType)
+	if !ok {
+		return nil, hardError("%s is not a function", ft.GetDefinition())
+	}
+	if len(fn.Results) <= indx {
+		return nil, hardError("%d result is available but want the %d", len(fn.Results), indx)
+	}
+	return fn.Results[indx].Type, nil
+}
+
+// typeOfIndex return the type of the indx-th value of an expression, multi value expressions
+// are function calls, map index, type assertion and channel receive
+func (et *exprTyper) typeOfIndex(e ast.Expr, indx int) (Type, error) {
+	if indx == 0 {
+		return et.typeOf(e)
+	}
+	switch t := e.(type) {
+	case *ast.ParenExpr:
+		return et.typeOfIndex(t.X, indx)
+	case *ast.CallExpr:
+		return et.callType(t, indx)
+	case *ast.IndexExpr, *ast.TypeAssertExpr:
+		if indx == 1 {
+			return et.ident("bool"), nil
+		}
+	case *ast.UnaryExpr:
+		if t.Op == token.ARROW && indx == 1 {
+			return et.ident("bool"), nil
+		}
+	}
+	return nil, hardError("expression has no %d value", indx+1)
+}
+
+// typeOf return the type of an expression
+func (et *exprTyper) typeOf(e ast.Expr) (Type, error) {
+	t, err := et.exprType(e)
+	if err == nil && t == nil {
+		// the types that are not supported, like the generic ones
+		return nil, softError("unsupported type for %s", getSource(e, et.file.src))
+	}
+	return t, err
+}
+
+func (et *exprTyper) exprType(e ast.Expr) (Type, error) {
+	switch t := e.(type) {
+	case *ast.BasicLit:
+		return et.literal(t), nil
+	case *ast.Ident:
+		return et.identType(t)
+	case *ast.ParenExpr:
+		return et.typeOf(t.X)
+	case *ast.CompositeLit:
+		if t.Type == nil {
+			return nil, softError("composite literal without type")
+		}
+		return getType(t.Type, et.file.src, et.file, et.pkg), nil
+	case *ast.FuncLit:
+		return getType(t.Type, et.file.src, et.file, et.pkg), nil
+	case *ast.StarExpr:
+		x, err := et.typeOf(t.X)
+		if err != nil {
+			return nil, err
+		}
+		if st, ok := et.underlying(x).(*StarType); ok {
+			return st.Target, nil
+		}
+		return nil, hardError("invalid indirect of %s", x.GetDefinition())
+	case *ast.UnaryExpr:
+		x, err := et.typeOf(t.X)
+		if err != nil {
+			return nil, err
+		}
+		switch t.Op {
+		case token.AND:
+			return &StarType{srcBase: srcBase{pkg: et.pkg}, Target: x}, nil
+		case token.NOT:
+			return et.ident("bool"), nil
+		case token.ARROW:
+			if ct, ok := et.underlying(x).(*ChannelType); ok {
+				return ct.Type, nil
+			}
+			return nil, hardError("receive from non channel type %s", x.GetDefinition())
+		}
+		return x, nil
+	case *ast.BinaryExpr:
+		return et.binaryType(t)
+	case *ast.IndexExpr:
+		return et.indexType(t)
+	case *ast.SliceExpr:
+		x, err := et.typeOf(t.X)
+		if err != nil {
+			return nil, err
+		}
+		switch u := et.underlying(x).(type) {
+		case *ArrayType:
+			if !u.Slice {
+				return &ArrayType{srcBase: srcBase{pkg: et.pkg}, Slice: true, Type: u.Type}, nil
+			}
+		case *EllipsisType:
+			return &ArrayType{srcBase: srcBase{pkg: et.pkg}, Slice: true, Type: u.Type}, nil
+		case *StarType:
+			if at, ok := et.underlying(u.Target).(*ArrayType); ok {
+				return &ArrayType{srcBase: srcBase{pkg: et.pkg}, Slice: true, Type: at.Type}, nil
+			}
+		}
+		return x, nil
+	case *ast.TypeAssertExpr:
+		if t.Type == nil {
+			return nil, hardError("invalid use of .(type)")
+		}
+		return getType(t.Type, et.file.src, et.file, et.pkg), nil
+	case *ast.SelectorExpr:
+		return et.selectorType(t)
+	case *ast.CallExpr:
+		return et.callType(t, 0)
+	}
+
+	return nil, softError("unsupported expression %T", e)
+}
+
+func (et *exprTyper) binaryType(t *ast.BinaryExpr) (Type, error) {
+	switch t.Op {
+	case token.EQL, token.NEQ, token.LSS, token.LEQ, token.GTR, token.GEQ, token.LAND, token.LOR:
+		return et.ident("bool"), nil
+	case token.SHL, token.SHR:
+		return et.typeOf(t.X)
+	}
+
+	ux, uy := et.untyped(t.X), et.untyped(t.Y)
+	switch {
+	case ux && !uy:
+		return et.typeOf(t.Y)
+	case ux && uy:
+		x, err := et.typeOf(t.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := et.typeOf(t.Y)
+		if err != nil {
+			return nil, err
+		}
+		if untypedRank[y.GetDefinition()] > untypedRank[x.GetDefinition()] {
+			return y, nil
+		}
+		return x, nil
+	}
+	return et.typeOf(t.X)
+}
+
+func (et *exprTyper) indexType(t *ast.IndexExpr) (Type, error) {
+	x, err := et.typeOf(t.X)
+	if err != nil {
+		return nil, err
+	}
+	u := et.underlying(x)
+	if st, ok := u.(*StarType); ok {
+		// pointer to array
+		u = et.underlying(st.Target)
+	}
+	switch c := u.(type) {
+	case *MapType:
+		return c.Value, nil
+	case *ArrayType:
+		return c.Type, nil
+	case *EllipsisType:
+		return c.Type, nil
+	case *IdentType:
+		if c.Ident == "string" {
+			return et.ident("byte"), nil
+		}
+	}
+	return nil, hardError("can not index %s", x.GetDefinition())
+}
+
+// bind try to find the type of a variable, if it fails, then a diagnostic is
+// added to the package, or an error if the variable is invalid
+func (et *exprTyper) bind(v *Variable) error {
+	if v.Type != nil || v.expr == nil {
+		return nil
+	}
+	_, err := et.varType(v)
+	if err == nil {
+		return nil
+	}
+	if _, call := v.expr.(*ast.CallExpr); call && !isSoft(err) {
+		return err
+	}
+
+	et.pkg.Diagnostics = append(et.pkg.Diagnostics, Diagnostic{
+		Pos:     v.file.position(v.expr.Pos()),
+		Message: fmt.Sprintf("can not infer the type of variable %s: %s", v.Name, err),
+	})
+	return nil
+}
@@ -0,0 +1,135 @@
+package humanize
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+var typr = `
+package test
+
+import "github.com/goraz/humanize/fixture"
+
+type Foo struct {
+	Name  string
+	Inner *Foo
+}
+
+func (f *Foo) With(n int) *Foo {
+	return f
+}
+
+func (f Foo) Build() (map[string]int, error) {
+	return nil, nil
+}
+
+func NewFoo() *Foo {
+	return &Foo{}
+}
+
+type Count int
+
+const c1 = 10
+
+var (
+	ptr    = &Foo{}
+	a, b   = 1, 2.5
+	sum    = a + a
+	cnt    = Count(1) * 2
+	m      = map[string][]Count{}
+	item   = m["k"]
+	first  = item[0]
+	v, ok  = m["k"]
+	fn     = func(x int) string { return "" }
+	called = fn(1)
+	sel    = fixture.Y
+	chain  = NewFoo().With(1).Build
+	res, e = NewFoo().With(1).Build()
+	field  = ptr.Inner.Name
+	neg    = -c1
+	cmp    = a > 1
+	ch     = make(chan *Foo)
+	recv   = <-ch
+	ln     = len(m)
+	nw     = new(Foo)
+	app    = append([]Count{}, 1)
+	sub    = "test"[1:]
+	deref  = *ptr
+	cnv    = (*Foo)(nil)
+	rn     = 'a' + 1
+	im     = 2i * 1.5
+)
+
+var unknown = missing.Value
+
+var nothing = nil
+
+type Box[T any] struct{}
+
+var generic = Box[int]{}
+`
+
+func TestTyper(t *testing.T) {
+	Convey("Typer test", t, func() {
+		var p = &Package{}
+		f, err := ParseFile(typr, p)
+		So(err, ShouldBeNil)
+		p.Files = append(p.Files, f)
+		So(lateBind(p), ShouldBeNil)
+
+		types := map[string]string{
+			"ptr":    "*Foo",
+			"a":      "int",
+			"b":      "float64",
+			"sum":    "int",
+			"cnt":    "Count",
+			"m":      "map[string][]Count",
+			"item":   "[]Count",
+			"first":  "Count",
+			"v":      "[]Count",
+			"ok":     "bool",
+			"fn":     "func (int) string",
+			"called": "string",
+			"sel":    "*os.File",
+			"chain":  "func () (map[string]int,error)",
+			"res":    "map[string]int",
+			"e":      "error",
+			"field":  "string",
+			"neg":    "int",
+			"cmp":    "bool",
+			"ch":     "chan *Foo",
+			"recv":   "*Foo",
+			"ln":     "int",
+			"nw":     "*Foo",
+			"app":    "[]Count",
+			"sub":    "string",
+			"deref":  "Foo",
+			"cnv":    "*Foo",
+			"rn":     "rune",
+			"im":     "complex128",
+		}
+		for name, def := range types {
+			v, err := p.FindVariable(name)
+			So(err, ShouldBeNil)
+			So(v.Type, ShouldNotBeNil)
+			So(v.Type.GetDefinition(), ShouldEqual, def)
+		}
+
+		Convey("diagnostics", func() {
+			So(len(p.Diagnostics), ShouldEqual, 3)
+			So(p.Diagnostics[0].Message, ShouldContainSubstring, "variable unknown")
+			So(p.Diagnostics[0].Pos.Line, ShouldEqual, 56)
+			So(p.Diagnostics[1].Message, ShouldContainSubstring, "untyped nil")
+			v, err := p.FindVariable("nothing")
+			So(err, ShouldBeNil)
+			So(v.Type, ShouldBeNil)
+
+			// the generic types are not supported, it is a diagnostic not a nil type
+			So(p.Diagnostics[2].Message, ShouldContainSubstring, "unsupported type for Box[int]{}")
+			v, err = p.FindVariable("generic")
+			So(err, ShouldBeNil)
+			So(v.Type, ShouldBeNil)
+		})
+	})
+}
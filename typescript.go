@@ -0,0 +1,309 @@
+package humanize
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/constant"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var tsBasic = map[string]string{
+	"string": "string", "bool": "boolean",
+	"int": "number", "int8": "number", "int16": "number", "int32": "number", "int64": "number",
+	"uint": "number", "uint8": "number", "uint16": "number", "uint32": "number", "uint64": "number",
+	"uintptr": "number", "byte": "number", "rune": "number",
+	"float32": "number", "float64": "number",
+	"any": "unknown",
+}
+
+var tsWellKnown = map[string]string{
+	"time.Time":                "string",
+	"time.Duration":            "number",
+	"encoding/json.RawMessage": "unknown",
+}
+
+var tsIdent = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// tsBuilder create the declarations, the local types that are used are added to the
+// queue and the types from the other packages are imported
+type tsBuilder struct {
+	pkg    *Package
+	queue  []*TypeName
+	queued map[*TypeName]bool
+	// imports is module => name => the local name
+	imports map[string]map[string]string
+	used    map[string]bool
+}
+
+// TypeScript create the TypeScript declarations of the types in the package, all the
+// exported types if there is no name. the structs are interfaces with the json names,
+// the enums are unions of the values and the other types are aliases. the types from
+// the other packages are imported from ./<package name>
+func TypeScript(p *Package, names ...string) (string, error) {
+	tb := &tsBuilder{
+		pkg:     p,
+		queued:  make(map[*TypeName]bool),
+		imports: make(map[string]map[string]string),
+		used:    make(map[string]bool),
+	}
+	for _, f := range p.Files {
+		for _, tn := range f.Types {
+			tb.used[tn.Name] = true
+			if len(names) == 0 && isExported(tn.Name) {
+				tb.local(tn)
+			}
+		}
+	}
+	for _, name := range names {
+		tn, err := p.FindType(name)
+		if err != nil {
+			return "", err
+		}
+		tb.local(tn)
+	}
+
+	decls := make(map[string]string)
+	for i := 0; i < len(tb.queue); i++ {
+		tn := tb.queue[i]
+		decl, err := tb.decl(tn)
+		if err != nil {
+			return "", fmt.Errorf("type %s: %w", tn.Name, err)
+		}
+		decls[tn.Name] = decl
+	}
+
+	buf := &bytes.Buffer{}
+	modules := make([]string, 0, len(tb.imports))
+	for m := range tb.imports {
+		modules = append(modules, m)
+	}
+	sort.Strings(modules)
+	for _, m := range modules {
+		var list []string
+		for name, local := range tb.imports[m] {
+			if name != local {
+				name += " as " + local
+			}
+			list = append(list, name)
+		}
+		sort.Strings(list)
+		fmt.Fprintf(buf, "import type { %s } from %q;\n", strings.Join(list, ", "), m)
+	}
+	// the declarations are sorted, so the output is the same for the same types
+	sorted := make([]string, 0, len(decls))
+	for name := range decls {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+	for i, name := range sorted {
+		if i > 0 || len(modules) > 0 {
+			buf.WriteString("\n")
+		}
+		buf.WriteString(decls[name])
+	}
+	return buf.String(), nil
+}
+
+func (tb *tsBuilder) local(tn *TypeName) string {
+	if !tb.queued[tn] {
+		tb.queued[tn] = true
+		tb.queue = append(tb.queue, tn)
+	}
+	return tn.Name
+}
+
+// imported add the import of the type and return the local name, the name is
+// prefixed with the package name if it is used
+func (tb *tsBuilder) imported(tn *TypeName, p *Package) string {
+	module := "./" + p.Name
+	names, ok := tb.imports[module]
+	if !ok {
+		names = make(map[string]string)
+		tb.imports[module] = names
+	}
+	if local, ok := names[tn.Name]; ok {
+		return local
+	}
+	local := tn.Name
+	if tb.used[local] {
+		local = pascalCase(p.Name) + tn.Name
+		for i := 2; tb.used[local]; i++ {
+			local = pascalCase(p.Name) + tn.Name + strconv.Itoa(i)
+		}
+	}
+	tb.used[local] = true
+	names[tn.Name] = local
+	return local
+}
+
+func jsDoc(buf *bytes.Buffer, indent string, d Docs) {
+	text := strings.Replace(description(d), "*/", "*\\/", -1)
+	if text == "" {
+		return
+	}
+	lines := strings.Split(text, "\n")
+	if len(lines) == 1 {
+		fmt.Fprintf(buf, "%s/** %s */\n", indent, lines[0])
+		return
+	}
+	buf.WriteString(indent + "/**\n")
+	for _, l := range lines {
+		buf.WriteString(strings.TrimRight(indent+" * "+l, " ") + "\n")
+	}
+	buf.WriteString(indent + " */\n")
+}
+
+// decl return the declaration of the local type
+func (tb *tsBuilder) decl(tn *TypeName) (string, error) {
+	buf := &bytes.Buffer{}
+	jsDoc(buf, "", tn.Docs)
+	if e, err := tb.pkg.FindEnum(tn.Name); err == nil {
+		var (
+			values []string
+			seen   = make(map[string]bool)
+		)
+		for _, v := range e.Values {
+			if v.Value == nil || v.Constant.Name == "_" {
+				continue
+			}
+			lit, err := tsLiteral(v.Value)
+			if err != nil {
+				return "", err
+			}
+			if !seen[lit] {
+				seen[lit] = true
+				values = append(values, lit)
+			}
+		}
+		fmt.Fprintf(buf, "export type %s = %s;\n", tn.Name, strings.Join(values, " | "))
+		return buf.String(), nil
+	}
+	if st, ok := tn.Type.(*StructType); ok {
+		obj, err := tb.object(st, tb.pkg, "")
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(buf, "export interface %s %s\n", tn.Name, obj)
+		return buf.String(), nil
+	}
+	t, err := tb.typ(tn.Type, tb.pkg, "")
+	if err != nil {
+		return "", err
+	}
+	fmt.Fprintf(buf, "export type %s = %s;\n", tn.Name, t)
+	return buf.String(), nil
+}
+
+// tsLiteral return the TypeScript literal of the constant
+func tsLiteral(v constant.Value) (string, error) {
+	switch v.Kind() {
+	case constant.String:
+		res, err := json.Marshal(constant.StringVal(v))
+		return string(res), err
+	case constant.Int:
+		return v.ExactString(), nil
+	case constant.Float:
+		f, _ := constant.Float64Val(v)
+		return strconv.FormatFloat(f, 'g', -1, 64), nil
+	case constant.Bool:
+		return v.ExactString(), nil
+	}
+	return "", fmt.Errorf("enum value %s can not be mapped", v)
+}
+
+// object return the object type of the struct, the fields are in the json encoding
+func (tb *tsBuilder) object(st *StructType, p *Package, indent string) (string, error) {
+	fields, err := structFields(st, p, "json")
+	if err != nil {
+		return "", err
+	}
+	buf := &bytes.Buffer{}
+	buf.WriteString("{\n")
+	for _, wf := range fields {
+		inner := indent + "  "
+		t := wf.Type
+		_, pointer := t.(*StarType)
+		typ, err := tb.typ(t, wf.Package, inner)
+		if err != nil {
+			return "", fmt.Errorf("field %s: %w", wf.Name, err)
+		}
+		if wf.AsString && (typ == "number" || typ == "boolean") {
+			typ = "string"
+		}
+		name := wf.Name
+		if !tsIdent.MatchString(name) {
+			n, _ := json.Marshal(name)
+			name = string(n)
+		}
+		if wf.OmitEmpty || pointer {
+			name += "?"
+		}
+		jsDoc(buf, inner, wf.Docs)
+		fmt.Fprintf(buf, "%s%s: %s;\n", inner, name, typ)
+	}
+	buf.WriteString(indent + "}")
+	return buf.String(), nil
+}
+
+// typ return the TypeScript type, the indent is for the inline objects
+func (tb *tsBuilder) typ(t Type, p *Package, indent string) (string, error) {
+	switch x := t.(type) {
+	case *IdentType:
+		if s, ok := tsBasic[x.Ident]; ok {
+			return s, nil
+		}
+		if predeclared[x.Ident] {
+			return "", fmt.Errorf("%s can not be mapped", x.Ident)
+		}
+	case *SelectorType:
+		if x.pkg != nil {
+			if s, ok := tsWellKnown[x.pkg.Path+"."+x.Type.GetDefinition()]; ok {
+				return s, nil
+			}
+		}
+	case *StarType:
+		return tb.typ(x.Target, p, indent)
+	case *EllipsisType:
+		return tb.typ(x.ArrayType, p, indent)
+	case *ArrayType:
+		if isBytes(x) {
+			return "string", nil
+		}
+		elem, err := tb.typ(x.Type, p, indent)
+		if err != nil {
+			return "", err
+		}
+		if strings.Contains(elem, "|") {
+			elem = "(" + elem + ")"
+		}
+		return elem + "[]", nil
+	case *MapType:
+		key, err := tb.typ(x.Key, p, indent)
+		if err != nil {
+			return "", err
+		}
+		value, err := tb.typ(x.Value, p, indent)
+		if err != nil {
+			return "", err
+		}
+		return "Record<" + key + ", " + value + ">", nil
+	case *InterfaceType:
+		return "unknown", nil
+	case *StructType:
+		return tb.object(x, p, indent)
+	default:
+		return "", fmt.Errorf("%s can not be mapped", goSource(t))
+	}
+	tn, tp := newTyper(p, nil).typeName(t)
+	if tn == nil {
+		return "", fmt.Errorf("type %s not found", goSource(t))
+	}
+	if tp == tb.pkg {
+		return tb.local(tn), nil
+	}
+	return tb.imported(tn, tp), nil
+}
@@ -0,0 +1,134 @@
+package humanize
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+var tsSrc = `package test
+
+import (
+	"image"
+	"time"
+)
+
+// Status is the state
+// of the order
+type Status string
+
+const (
+	Open   Status = "open"
+	Closed Status = "closed"
+)
+
+type Level int
+
+const (
+	Low Level = iota
+	High
+)
+
+// Point is a local point
+type Point struct {
+	X int
+}
+
+// Order is an order
+type Order struct {
+	Base
+	// Name of the order
+	Name    string            ` + "`json:\"name\"`" + `
+	Note    *string           ` + "`json:\"note\"`" + `
+	Count   int               ` + "`json:\"count,omitempty,string\"`" + `
+	Status  Status            ` + "`json:\"status\"`" + `
+	Levels  []Level           ` + "`json:\"levels\"`" + `
+	Data    []byte            ` + "`json:\"data\"`" + `
+	Meta    map[string]any    ` + "`json:\"meta\"`" + `
+	Created time.Time         ` + "`json:\"created\"`" + `
+	Where   image.Point       ` + "`json:\"where\"`" + `
+	Here    Point             ` + "`json:\"here\"`" + `
+	Items   []*item           ` + "`json:\"items\"`" + `
+	Inner   struct{ A bool }  ` + "`json:\"inner-data\"`" + `
+	Skip    string            ` + "`json:\"-\"`" + `
+}
+
+type Base struct {
+	ID int64 ` + "`json:\"id\"`" + `
+}
+
+type item struct {
+	Price float64
+}
+
+type IDs []string
+
+type Bad struct {
+	Fn func()
+}
+`
+
+func TestTypeScript(t *testing.T) {
+	Convey("TypeScript test", t, func() {
+		var p = &Package{Path: "example.com/test", Name: "test"}
+		f, err := ParseFile(tsSrc, p)
+		So(err, ShouldBeNil)
+		p.Files = append(p.Files, f)
+		So(lateBind(p), ShouldBeNil)
+
+		src, err := TypeScript(p, "Order", "IDs")
+		So(err, ShouldBeNil)
+		So(src, ShouldEqual, `import type { Point as ImagePoint } from "./image";
+
+export type IDs = string[];
+
+export type Level = 0 | 1;
+
+/** Order is an order */
+export interface Order {
+  /** Name of the order */
+  name: string;
+  note?: string;
+  count?: string;
+  status: Status;
+  levels: Level[];
+  data: string;
+  meta: Record<string, unknown>;
+  created: string;
+  where: ImagePoint;
+  here: Point;
+  items: item[];
+  "inner-data": {
+    A: boolean;
+  };
+  id: number;
+}
+
+/** Point is a local point */
+export interface Point {
+  X: number;
+}
+
+/**
+ * Status is the state
+ * of the order
+ */
+export type Status = "open" | "closed";
+
+export interface item {
+  Price: number;
+}
+`)
+
+		Convey("all the exported types", func() {
+			_, err := TypeScript(p)
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldEqual, "type Bad: field Fn: func() can not be mapped")
+		})
+
+		Convey("missing type", func() {
+			_, err := TypeScript(p, "Missing")
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
@@ -7,27 +7,45 @@ import (
 
 // Variable is a string represent of a function parameter
 type Variable struct {
-	Name string
-	Type Type
-	Docs Docs
+	Name        string
+	Type        Type
+	Docs        Docs
+	Annotations Annotations
+	Directives  []*Directive
+	// EmbedFiles is the go:embed data, nil if the variable is not an embed variable
+	EmbedFiles *EmbedFiles
+	// GroupDocs is the docs of the declaration group, like var ( ... )
+	GroupDocs Docs
+	// SpecDocs is the docs of the declaration itself, Docs is both of them
+	SpecDocs Docs
+	// Comment is the trailing comment, and the inline comments after the name
+	Comment Docs
+	// Initializer is the source of the value, for multi value expressions like
+	// var a, b = f() it is the same for all the variables
+	Initializer string
+	// Expression is the structured form of the initializer
+	Expression *Expression
 
-	caller *ast.CallExpr
-	indx   int
+	expr     ast.Expr // the value, if the variable has one
+	indx     int      // the index in the value, if the value is a multi value expression
+	typeExpr ast.Expr // the explicit type, if the variable has one
+	decl     *ast.GenDecl
+	file     *File
 }
 
 func variableFromValue(name string, indx int, e []ast.Expr, src string, f *File, p *Package) *Variable {
 	var t Type
-	var caller *ast.CallExpr
-	var ok bool
-	first := e[0]
-	// if the caller is a CallExpr, then late bind will take care of it
-	if caller, ok = first.(*ast.CallExpr); !ok {
-		switch data := e[indx].(type) {
+	expr, indx := valueAt(e, indx)
+	// the simple ones are here, the rest is handled by the late bind
+	if indx == 0 {
+		switch data := expr.(type) {
 		case *ast.CompositeLit:
 			//if data.Type != nil {
 			// the type is here
 			t = getType(data.Type, src, f, p)
 			//}
+		case *ast.FuncLit:
+			t = getType(data.Type, src, f, p)
 		case *ast.BasicLit:
 			switch data.Kind {
 			case token.INT:
@@ -43,12 +61,12 @@ func variableFromValue(name string, indx int, e []ast.Expr, src string, f *File,
 			case token.IMAG:
 				t = &IdentType{
 					srcBase{p, getSource(data, src)},
-					"complex64",
+					"complex128",
 				}
 			case token.CHAR:
 				t = &IdentType{
 					srcBase{p, getSource(data, src)},
-					"char",
+					"rune",
 				}
 			case token.STRING:
 				t = &IdentType{
@@ -62,16 +80,29 @@ func variableFromValue(name string, indx int, e []ast.Expr, src string, f *File,
 		}
 	}
 	return &Variable{
-		Name:   name,
-		Type:   t,
-		caller: caller,
-		indx:   indx,
+		Name: name,
+		Type: t,
+		expr: expr,
+		indx: indx,
+		file: f,
+	}
+}
+
+// valueAt return the value expression of the i-th name, and the index in that value
+func valueAt(e []ast.Expr, i int) (ast.Expr, int) {
+	if len(e) == 1 {
+		return e[0], i
 	}
+	// each name has its own value
+	return e[i], 0
 }
+
 func variableFromExpr(name string, e ast.Expr, src string, f *File, p *Package) *Variable {
 	return &Variable{
-		Name: name,
-		Type: getType(e, src, f, p),
+		Name:     name,
+		Type:     getType(e, src, f, p),
+		typeExpr: e,
+		file:     f,
 	}
 }
 
@@ -83,12 +114,24 @@ func NewVariable(v *ast.ValueSpec, c *ast.CommentGroup, src string, f *File, p *
 		var n *Variable
 		if v.Type != nil {
 			n = variableFromExpr(name, v.Type, src, f, p)
+			if len(v.Values) != 0 {
+				n.expr, n.indx = valueAt(v.Values, i)
+			}
 		} else {
 			if len(v.Values) != 0 {
 				n = variableFromValue(name, i, v.Values, src, f, p)
 			}
 		}
 		n.Docs = docsFromNodeDoc(c, v.Doc)
+		n.GroupDocs, n.SpecDocs = docsFromNodeDoc(c), docsFromNodeDoc(v.Doc)
+		n.Comment = append(inlineComment(f, v.Names, i, valueSpecEnd(v)), docsFromNodeDoc(v.Comment)...)
+		n.Annotations = annotationsFromNodeDoc(f, c, v.Doc)
+		n.Directives = directivesFromNodeDoc(f, c, v.Doc)
+		if n.expr != nil {
+			n.Initializer = getSource(n.expr, src)
+			n.Expression = newExpression(n.expr, src, f, p)
+			n.Expression.nameLiterals(n.Name)
+		}
 		res = append(res, n)
 	}
 
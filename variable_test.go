@@ -23,6 +23,8 @@ var i5 = []int{1,2,3,4,5}
 
 var i6 = 10i
 
+var i7 = func(a int) error { return nil }
+
 `
 
 func TestVariable(t *testing.T) {
@@ -68,7 +70,7 @@ func TestVariable(t *testing.T) {
 			So(err, ShouldBeNil)
 			So(i.Name, ShouldEqual, "i4")
 			So(i.Name, ShouldEqual, "i4")
-			So(i.Type.(*IdentType).Ident, ShouldEqual, "char")
+			So(i.Type.(*IdentType).Ident, ShouldEqual, "rune")
 		})
 
 		Convey("by value i5", func() {
@@ -84,7 +86,15 @@ func TestVariable(t *testing.T) {
 			So(err, ShouldBeNil)
 			So(i.Name, ShouldEqual, "i6")
 			So(i.Name, ShouldEqual, "i6")
-			So(i.Type.(*IdentType).Ident, ShouldEqual, "complex64")
+			So(i.Type.(*IdentType).Ident, ShouldEqual, "complex128")
+		})
+
+		Convey("by value i7", func() {
+			i, err := p.FindVariable("i7")
+			So(err, ShouldBeNil)
+			So(i.Name, ShouldEqual, "i7")
+			So(i.Type.GetDefinition(), ShouldEqual, "func (int) error")
+			So(i.Expression.Function.Name, ShouldEqual, "i7.func1")
 		})
 	})
 }